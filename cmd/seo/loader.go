@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/quadgod/seo/pkg/generation"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/quadgod/seo/pkg/snapshot"
+	"github.com/quadgod/seo/pkg/urlnorm"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// generationLoader загружает генерацию и публикует ее снапшот в holder.
+// Если задан snapshotDir, то сначала пытается поднять снапшот из локального файла, сохраненного с теми же настройками,
+// проверив в базе только существование генерации, а после загрузки из базы сохраняет снапшот в файл
+type generationLoader struct {
+	pool        *pgxpool.Pool
+	holder      *snapshot.Holder
+	logger      *slog.Logger
+	snapshotDir string
+	// normalizer нормализует url строк генерации, должен совпадать с нормализатором сервера
+	normalizer *urlnorm.Normalizer
+	// ignoreQuery правило игнорируемых при поиске параметров query, применяется к каждой загруженной генерации
+	ignoreQuery radixtrie.QueryIgnoreFunc
+	// backtracking включает поиск с возвратом (см. radixtrie.Trie.SetBacktracking) для каждой загруженной генерации
+	backtracking bool
+	// stats публикует статистику деревьев каждой загруженной генерации
+	stats *statsPublisher
+	// fingerprint отпечаток настроек загрузки и поиска, снапшоты с другим отпечатком строятся заново, см. snapshotFingerprint
+	fingerprint string
+}
+
+// snapshotFingerprint отпечаток настроек, от которых зависят деревья генерации и поиск по ним:
+// шаги нормализации, игнорируемые параметры query и режим поиска с возвратом.
+// Снапшот, сохраненный с другими настройками, не переиспользуется после их изменения
+func snapshotFingerprint(normalizer urlnorm.Options, ignoreQuery []string, backtracking bool) string {
+	keys := slices.Sorted(slices.Values(ignoreQuery))
+	sum := sha256.Sum256(fmt.Appendf(nil, "%+v|%s|%t", normalizer, strings.Join(keys, ","), backtracking))
+	return hex.EncodeToString(sum[:])
+}
+
+func (l *generationLoader) Load(ctx context.Context, gen time.Time) error {
+	startedAt := time.Now()
+
+	if snap := l.readSnapshotFile(ctx, gen); snap != nil {
+		snap.StartedAt = startedAt
+		l.configure(snap.Sites)
+		l.holder.Swap(snap)
+		l.stats.Publish(snap)
+		l.logger.Info(
+			"generation swapped from snapshot file",
+			"generation", gen,
+			"publishLatency", l.holder.Metrics().LastPublishLatency,
+		)
+		return nil
+	}
+
+	sites, stats, err := generation.Load(ctx, l.pool, gen, l.normalizer)
+	if err != nil {
+		return err
+	}
+
+	for _, rejected := range stats.Rejected {
+		l.logger.Warn("seo row rejected", "host", rejected.Host, "url", rejected.URL, "reason", rejected.Reason)
+	}
+
+	for _, conflict := range stats.Conflicts {
+		l.logger.Warn("seo row conflicts", "host", conflict.Host, "url", conflict.URL, "existing", conflict.Existing)
+	}
+
+	l.configure(sites)
+	snap := snapshot.New(sites, gen)
+	snap.StartedAt = startedAt
+	l.holder.Swap(snap)
+	l.stats.Publish(snap)
+
+	l.logger.Info(
+		"generation swapped",
+		"generation", gen,
+		"rows", stats.Rows,
+		"inserted", stats.Inserted,
+		"redirects", stats.Redirects,
+		"sites", stats.Sites,
+		"rejected", len(stats.Rejected),
+		"conflicts", len(stats.Conflicts),
+		"duration", stats.Duration,
+		"publishLatency", l.holder.Metrics().LastPublishLatency,
+	)
+
+	l.writeSnapshotFile(snap)
+
+	return nil
+}
+
+// configure применяет настройки поиска к деревьям всех сайтов генерации.
+// Статические сегменты сравниваются без учета регистра, если нормализатор приводит шаблоны к нижнему регистру
+func (l *generationLoader) configure(sites *site.Sites) {
+	for _, host := range sites.Hosts() {
+		trie := sites.Trie(host)
+		trie.SetQueryIgnore(l.ignoreQuery)
+		trie.SetBacktracking(l.backtracking)
+		trie.SetCaseFolding(l.normalizer.Options().Lowercase)
+	}
+}
+
+func (l *generationLoader) readSnapshotFile(ctx context.Context, gen time.Time) *snapshot.Snapshot {
+	if l.snapshotDir == "" {
+		return nil
+	}
+
+	snap, err := snapshot.ReadFile(l.snapshotDir, gen, l.fingerprint)
+	if err != nil {
+		if errors.Is(err, snapshot.ErrFingerprintMismatch) {
+			l.logger.Info("snapshot file was built with other settings, loading from database", "generation", gen)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			l.logger.Warn("read snapshot file errors", "generation", gen, "error", err)
+		}
+		return nil
+	}
+
+	exists, err := generation.Exists(ctx, l.pool, gen)
+	if err != nil {
+		l.logger.Warn("validate snapshot generation errors", "generation", gen, "error", err)
+		return nil
+	}
+
+	if !exists {
+		l.logger.Warn("snapshot generation not found in database", "generation", gen)
+		return nil
+	}
+
+	return snap
+}
+
+func (l *generationLoader) writeSnapshotFile(snap *snapshot.Snapshot) {
+	if l.snapshotDir == "" {
+		return
+	}
+
+	// снапшот только ускоряет следующий старт пода, поэтому ошибки записи не прерывают загрузку
+	if err := snapshot.WriteFile(l.snapshotDir, snap, l.fingerprint); err != nil {
+		l.logger.Warn("write snapshot file errors", "generation", snap.Generation, "error", err)
+		return
+	}
+
+	if err := snapshot.RemoveStale(l.snapshotDir, snap.Generation); err != nil {
+		l.logger.Warn("remove stale snapshot files errors", "error", err)
+	}
+}
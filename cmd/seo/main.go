@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"github.com/quadgod/seo/pkg/locale"
+	seoLogger "github.com/quadgod/seo/pkg/logger"
+	"github.com/quadgod/seo/pkg/pgm/db"
+	"github.com/quadgod/seo/pkg/podstate"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/server"
+	"github.com/quadgod/seo/pkg/snapshot"
+	"github.com/quadgod/seo/pkg/urlnorm"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	logLevel := new(slog.LevelVar)
+	logLevel.Set(slog.LevelInfo)
+	logger := seoLogger.CreateLogger(logLevel)
+
+	var addr, adminAddr, connectionString, snapshotDir, ignoreQuery, normalize, trailingSlash, defaultLocale, pathLocales string
+	var interval time.Duration
+	var backtracking bool
+
+	flag.StringVar(&addr, "addr", ":8080", "http listen address")
+	flag.StringVar(&adminAddr, "adminAddr", "", "http listen address of the debug routes (explain, expvar), disabled if empty")
+	flag.StringVar(&connectionString, "connectionString", os.Getenv("DATABASE_URL"), "connection string")
+	flag.StringVar(&snapshotDir, "snapshotDir", "", "directory for local generation snapshots, disabled if empty")
+	flag.StringVar(
+		&ignoreQuery,
+		"ignoreQuery",
+		strings.Join(radixtrie.DefaultIgnoredQueryKeys, ","),
+		"comma separated query keys ignored by the lookups unless declared by the pattern, key* is a prefix",
+	)
+	flag.StringVar(
+		&normalize,
+		"normalize",
+		urlnorm.DefaultSteps,
+		"comma separated url normalization steps: case, slashes, dots, decode, host",
+	)
+	flag.StringVar(&trailingSlash, "trailingSlash", "strip", "trailing slash policy of url normalization: keep, strip or add")
+	flag.StringVar(&defaultLocale, "defaultLocale", "", "locale of the declarations without locale, e.g. ru")
+	flag.StringVar(&pathLocales, "pathLocales", "", "comma separated locales whose urls start with the locale prefix, e.g. en,de")
+	flag.BoolVar(
+		&backtracking,
+		"backtracking",
+		false,
+		"try the named parameter and wildcard alternatives of the previous segments when a static branch does not match",
+	)
+	flag.DurationVar(&interval, "interval", podstate.DefaultInterval, "pod heartbeat and generation poll interval")
+
+	flag.Parse()
+
+	if connectionString == "" {
+		log.Fatalf("arguments validation errors: connection string is required")
+	}
+
+	normalizerOptions, err := urlnorm.ParseOptions(normalize, trailingSlash)
+	if err != nil {
+		log.Fatalf("arguments validation errors: %v", err)
+	}
+	normalizer := urlnorm.New(normalizerOptions)
+
+	locales, err := parseLocales(defaultLocale, pathLocales)
+	if err != nil {
+		log.Fatalf("arguments validation errors: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := db.Connect(ctx, connectionString)
+	if err != nil {
+		log.Fatalf("database connection errors: %v", err)
+	}
+	defer pool.Close()
+
+	holder := new(snapshot.Holder)
+
+	stats := new(statsPublisher)
+	expvar.Publish("seoGeneration", expvar.Func(stats.Value))
+
+	ignoreQueryKeys := splitList(ignoreQuery)
+	loader := &generationLoader{
+		pool:         pool,
+		holder:       holder,
+		logger:       logger,
+		snapshotDir:  snapshotDir,
+		normalizer:   normalizer,
+		ignoreQuery:  radixtrie.IgnoreQueryKeys(ignoreQueryKeys...),
+		backtracking: backtracking,
+		stats:        stats,
+		fingerprint:  snapshotFingerprint(normalizerOptions, ignoreQueryKeys, backtracking),
+	}
+
+	controller, err := podstate.NewController(pool, loader.Load, podstate.Options{
+		HeartbeatInterval: interval,
+		PollInterval:      interval,
+		Logger:            logger,
+	})
+	if err != nil {
+		log.Fatalf("create pod state controller errors: %v", err)
+	}
+
+	seoServer := server.New(holder, normalizer, locales, logger)
+	expvar.Publish("seoServer", expvar.Func(func() any { return seoServer.Metrics() }))
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: seoServer.Handler(),
+	}
+
+	var adminServer *http.Server
+	if adminAddr != "" {
+		adminServer = &http.Server{
+			Addr:    adminAddr,
+			Handler: seoServer.AdminHandler(),
+		}
+
+		go func() {
+			// отладочные маршруты не нужны для обслуживания запросов, поэтому их ошибка не останавливает сервис
+			if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("admin http server errors", "error", err)
+			}
+		}()
+	}
+
+	controllerDone := make(chan error, 1)
+	go func() {
+		err := controller.Run(ctx)
+		if err != nil {
+			// без контроллера под никогда не загрузит генерацию, останавливаем сервер
+			stop()
+		}
+		controllerDone <- err
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("http server shutdown errors", "error", err)
+		}
+		if adminServer != nil {
+			if err := adminServer.Shutdown(shutdownCtx); err != nil {
+				logger.Error("admin http server shutdown errors", "error", err)
+			}
+		}
+	}()
+
+	logger.Info("seo server started", "addr", addr, "adminAddr", adminAddr, "hostname", controller.Hostname())
+
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("http server errors: %v", err)
+	}
+
+	if err := <-controllerDone; err != nil {
+		log.Fatalf("pod state controller errors: %v", err)
+	}
+}
+
+// parseLocales разбирает флаги локалей сервиса
+func parseLocales(defaultLocale, pathLocales string) (*locale.Resolver, error) {
+	resolver := new(locale.Resolver)
+
+	var err error
+	if resolver.Default, err = locale.Parse(defaultLocale); err != nil {
+		return nil, err
+	}
+
+	for _, raw := range splitList(pathLocales) {
+		prefix, err := locale.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		resolver.Prefixes = append(resolver.Prefixes, prefix)
+	}
+
+	return resolver, nil
+}
+
+// splitList разбирает список значений флага через запятую, пустые значения пропускаются
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/snapshot"
+	"sync/atomic"
+	"time"
+)
+
+// generationStats статистика деревьев загруженной генерации, публикуется в expvar (/debug/vars)
+type generationStats struct {
+	Generation time.Time   `json:"generation"`
+	Sites      []siteStats `json:"sites"`
+}
+
+// siteStats статистика дерева сайта, Site пустой для сайта по умолчанию
+type siteStats struct {
+	Site string `json:"site"`
+	radixtrie.Stats
+}
+
+// statsPublisher считает статистику деревьев после каждой загрузки генерации.
+// Обход деревьев дорогой, поэтому статистика считается один раз на загрузку, а не при каждом чтении
+type statsPublisher struct {
+	current atomic.Pointer[generationStats]
+}
+
+// Publish считает статистику деревьев сайтов снапшота
+func (p *statsPublisher) Publish(snap *snapshot.Snapshot) {
+	stats := &generationStats{Generation: snap.Generation}
+	for _, host := range snap.Sites.Hosts() {
+		stats.Sites = append(stats.Sites, siteStats{Site: host, Stats: snap.Sites.Trie(host).Stats()})
+	}
+
+	p.current.Store(stats)
+}
+
+// Value возвращает статистику последней загруженной генерации для expvar.Func, nil до первой загрузки
+func (p *statsPublisher) Value() any {
+	if stats := p.current.Load(); stats != nil {
+		return stats
+	}
+
+	return nil
+}
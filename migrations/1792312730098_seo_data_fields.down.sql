@@ -0,0 +1,8 @@
+alter table "public"."seo_declarations"
+    drop column if exists meta_header,
+    drop column if exists canonical_link,
+    drop column if exists og_title,
+    drop column if exists og_description,
+    drop column if exists og_image,
+    drop column if exists og_type,
+    drop column if exists hreflang;
@@ -0,0 +1,10 @@
+-- Колонки для всех полей radixtrie.SeoData.
+-- hreflang - массив альтернативных версий страницы [{"lang": "en", "href": "/en/..."}]
+alter table "public"."seo_declarations"
+    add column if not exists meta_header text default null,
+    add column if not exists canonical_link text default null,
+    add column if not exists og_title text default null,
+    add column if not exists og_description text default null,
+    add column if not exists og_image text default null,
+    add column if not exists og_type text default null,
+    add column if not exists hreflang jsonb not null default '{}'::jsonb;
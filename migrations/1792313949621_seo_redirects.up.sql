@@ -0,0 +1,14 @@
+-- Редиректы генерации, загружаются вместе с seo_declarations той же генерации.
+-- source - шаблон url, как url в seo_declarations (не должен совпадать с ними),
+-- target - путь или абсолютный http(s) url, может использовать параметры source: /catalog/{{category}},
+-- status_code - http код редиректа
+create table if not exists "public"."seo_redirects" (
+    generation timestamptz not null,
+    source text not null,
+    target text not null,
+    status_code smallint not null default 301 check (status_code in (301, 302, 307, 308)),
+    created_at timestamptz default CURRENT_TIMESTAMP,
+    updated_at timestamptz default CURRENT_TIMESTAMP,
+    primary key ("generation", "source")
+);
+create index if not exists seo_redirects_generation_idx on "public"."seo_redirects" ("generation");
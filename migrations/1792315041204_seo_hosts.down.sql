@@ -0,0 +1,9 @@
+alter table "public"."seo_redirects"
+    drop constraint if exists seo_redirects_pkey,
+    drop column if exists host,
+    add primary key ("generation", "source");
+
+alter table "public"."seo_declarations"
+    drop constraint if exists seo_declarations_pkey,
+    drop column if exists host,
+    add primary key ("generation", "url");
@@ -0,0 +1,11 @@
+-- Сайты генерации: декларации и редиректы относятся к хосту (shop.example.ru) или шаблону хоста (*.example.ru),
+-- пустой host - сайт по умолчанию, в котором ищутся url, не найденные в деревьях хоста
+alter table "public"."seo_declarations"
+    add column if not exists host text not null default '',
+    drop constraint if exists seo_declarations_pkey,
+    add primary key ("generation", "host", "url");
+
+alter table "public"."seo_redirects"
+    add column if not exists host text not null default '',
+    drop constraint if exists seo_redirects_pkey,
+    add primary key ("generation", "host", "source");
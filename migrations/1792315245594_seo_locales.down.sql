@@ -0,0 +1,4 @@
+alter table "public"."seo_declarations"
+    drop constraint if exists seo_declarations_pkey,
+    drop column if exists locale,
+    add primary key ("generation", "host", "url");
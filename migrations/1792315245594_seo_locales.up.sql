@@ -0,0 +1,6 @@
+-- Локализованные декларации: строки одного url с разными locale (en, en-gb) - данные шаблона на разных языках,
+-- пустая locale - нейтральные данные, которые отдаются, если для локалей запроса данных нет
+alter table "public"."seo_declarations"
+    add column if not exists locale text not null default '',
+    drop constraint if exists seo_declarations_pkey,
+    add primary key ("generation", "host", "url", "locale");
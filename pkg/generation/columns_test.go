@@ -0,0 +1,65 @@
+package generation
+
+import (
+	"context"
+	"github.com/quadgod/seo/pkg/pgm/db"
+	"github.com/quadgod/seo/pkg/pgtest"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/stretchr/testify/require"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// keyColumns колонки seo_declarations, которые не относятся к radixtrie.SeoData
+var keyColumns = []string{"generation", "host", "url", "locale", "created_at", "updated_at"}
+
+func seoDataColumns() []string {
+	columns := make([]string, 0)
+
+	dataType := reflect.TypeOf(radixtrie.SeoData{})
+	for i := 0; i < dataType.NumField(); i++ {
+		if column := dataType.Field(i).Tag.Get("db"); column != "" {
+			columns = append(columns, column)
+		}
+	}
+
+	return columns
+}
+
+func Test_declarationColumns(t *testing.T) {
+	t.Run("should load every SeoData field", func(t *testing.T) {
+		require.ElementsMatch(t, append([]string{"host", "url", "locale"}, seoDataColumns()...), declarationColumns)
+		require.Len(t, newDeclaration().targets(), len(declarationColumns))
+	})
+}
+
+func Test_seoDeclarationsTable(t *testing.T) {
+	ctx := context.Background()
+	connStr := pgtest.Run(t, "../../migrations")
+
+	pool, err := db.Connect(ctx, connStr)
+	require.Nil(t, err)
+	defer pool.Close()
+
+	t.Run("should keep seo_declarations columns in sync with SeoData", func(t *testing.T) {
+		rows, err := pool.Query(ctx, `
+			SELECT column_name FROM information_schema.columns
+			WHERE table_schema = 'public' AND table_name = 'seo_declarations';
+		`)
+		require.Nil(t, err)
+
+		columns := make([]string, 0)
+		for rows.Next() {
+			var column string
+			require.Nil(t, rows.Scan(&column))
+			columns = append(columns, column)
+		}
+		require.Nil(t, rows.Err())
+
+		expected := append(append([]string{}, keyColumns...), seoDataColumns()...)
+		sort.Strings(expected)
+		sort.Strings(columns)
+		require.Equal(t, expected, columns)
+	})
+}
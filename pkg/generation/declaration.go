@@ -0,0 +1,113 @@
+package generation
+
+import (
+	"fmt"
+	"github.com/quadgod/seo/pkg/locale"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/quadgod/seo/pkg/urlnorm"
+	"strings"
+)
+
+// declarationColumns колонки seo_declarations, которые читает загрузчик.
+// Порядок должен совпадать с declaration.targets
+var declarationColumns = []string{
+	"host",
+	"url",
+	"locale",
+	"meta_title",
+	"meta_description",
+	"meta_robots",
+	"meta_keywords",
+	"meta_header",
+	"canonical_link",
+	"og_title",
+	"og_description",
+	"og_image",
+	"og_type",
+	"faq",
+	"tags_cloud",
+	"hreflang",
+}
+
+var selectDeclarationsSql = fmt.Sprintf(`
+	SELECT %s
+	FROM "public"."seo_declarations"
+	WHERE generation = $1
+	ORDER BY host, url, locale;
+`, strings.Join(declarationColumns, ", "))
+
+// declaration строка seo_declarations
+type declaration struct {
+	host    string // хост или шаблон хоста сайта, после decode - приведенный site.ParseHost
+	url     string
+	locale  string // локаль данных, после decode - приведенная locale.Parse
+	pattern string // нормализованный url, с которым строка вставляется в дерево
+	data    *radixtrie.SeoData
+
+	faq       []byte
+	tagsCloud []byte
+	hreflang  []byte
+}
+
+func newDeclaration() *declaration {
+	return &declaration{data: new(radixtrie.SeoData)}
+}
+
+func (d *declaration) targets() []any {
+	return []any{
+		&d.host,
+		&d.url,
+		&d.locale,
+		&d.data.MetaTitle,
+		&d.data.MetaDescription,
+		&d.data.MetaRobots,
+		&d.data.MetaKeywords,
+		&d.data.MetaHeader,
+		&d.data.CanonicalLink,
+		&d.data.OgTitle,
+		&d.data.OgDescription,
+		&d.data.OgImage,
+		&d.data.OgType,
+		&d.faq,
+		&d.tagsCloud,
+		&d.hreflang,
+	}
+}
+
+// decode нормализует и валидирует хост, url и локаль, декодирует jsonb колонки и компилирует шаблоны
+func (d *declaration) decode(normalizer *urlnorm.Normalizer) (err error) {
+	if d.host, err = site.ParseHost(d.host); err != nil {
+		return err
+	}
+
+	if d.locale, err = locale.Parse(d.locale); err != nil {
+		return err
+	}
+
+	if d.pattern, err = normalizer.Pattern(d.url); err != nil {
+		return fmt.Errorf("normalize url: %w", err)
+	}
+
+	if err = radixtrie.ValidatePattern(d.pattern); err != nil {
+		return err
+	}
+
+	if d.data.Faq, err = decodeFaq(d.faq); err != nil {
+		return err
+	}
+
+	if d.data.TagsCloud, err = decodeTagsCloud(d.tagsCloud); err != nil {
+		return err
+	}
+
+	if d.data.Hreflang, err = decodeHreflang(d.hreflang); err != nil {
+		return err
+	}
+
+	if err = d.data.Compile(radixtrie.ParamKeys(d.pattern)); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	return nil
+}
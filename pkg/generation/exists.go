@@ -0,0 +1,21 @@
+package generation
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Exists проверяет, что в seo_declarations есть строки указанной генерации.
+// Используется, чтобы поднять под из локального снапшота, не загружая генерацию из базы
+func Exists(ctx context.Context, q Querier, generation time.Time) (bool, error) {
+	var exists bool
+	err := q.QueryRow(ctx, `
+		SELECT EXISTS (SELECT 1 FROM "public"."seo_declarations" WHERE generation = $1);
+	`, generation).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("check generation exists errors: %w", err)
+	}
+
+	return exists, nil
+}
@@ -0,0 +1,127 @@
+package generation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// hreflangRegexp язык (и регион) по ISO 639-1/ISO 3166-1 или x-default
+var hreflangRegexp = regexp.MustCompile(`^([a-z]{2,3}(-[A-Za-z]{2}|-[0-9]{3})?|x-default)$`)
+
+// jsonbItems поддерживает оба формата jsonb колонок: массив элементов
+// или объект {"items": [...]}. Значение по умолчанию '{}' означает отсутствие элементов
+type jsonbItems[T any] struct {
+	Items []T `json:"items"`
+}
+
+func decodeItems[T any](raw []byte) ([]T, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, nil
+	}
+
+	var items []T
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+
+	var obj jsonbItems[T]
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, err
+	}
+
+	return obj.Items, nil
+}
+
+// decodeFaq декодирует и валидирует колонку faq. Вопросы сортируются по полю order
+func decodeFaq(raw []byte) ([]radixtrie.FaqItem, error) {
+	items, err := decodeItems[radixtrie.FaqItem](raw)
+	if err != nil {
+		return nil, fmt.Errorf("faq: %w", err)
+	}
+
+	for i, item := range items {
+		if strings.TrimSpace(item.Question) == "" {
+			return nil, fmt.Errorf("faq: item %d: question is required", i)
+		}
+
+		if strings.TrimSpace(item.Answer) == "" {
+			return nil, fmt.Errorf("faq: item %d: answer is required", i)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Order < items[j].Order
+	})
+
+	return items, nil
+}
+
+// decodeTagsCloud декодирует и валидирует колонку tags_cloud
+func decodeTagsCloud(raw []byte) ([]radixtrie.TagsCloudItem, error) {
+	items, err := decodeItems[radixtrie.TagsCloudItem](raw)
+	if err != nil {
+		return nil, fmt.Errorf("tags_cloud: %w", err)
+	}
+
+	for i, item := range items {
+		if strings.TrimSpace(item.Label) == "" {
+			return nil, fmt.Errorf("tags_cloud: item %d: label is required", i)
+		}
+
+		if err = validateHref(item.Href); err != nil {
+			return nil, fmt.Errorf("tags_cloud: item %d: %w", i, err)
+		}
+
+		if item.Weight < 0 || math.IsNaN(item.Weight) || math.IsInf(item.Weight, 0) {
+			return nil, fmt.Errorf("tags_cloud: item %d: weight must be a non-negative number", i)
+		}
+	}
+
+	return items, nil
+}
+
+func validateHref(href string) error {
+	switch {
+	case href == "":
+		return errors.New("href is required")
+	case strings.HasPrefix(href, "//"):
+		return fmt.Errorf("href %q must not be protocol-relative", href)
+	case strings.HasPrefix(href, "/"), strings.HasPrefix(href, "https://"), strings.HasPrefix(href, "http://"):
+		return nil
+	default:
+		return fmt.Errorf("href %q must be a path or an absolute http(s) url", href)
+	}
+}
+
+// decodeHreflang декодирует и валидирует колонку hreflang
+func decodeHreflang(raw []byte) ([]radixtrie.HreflangAlternate, error) {
+	items, err := decodeItems[radixtrie.HreflangAlternate](raw)
+	if err != nil {
+		return nil, fmt.Errorf("hreflang: %w", err)
+	}
+
+	for i, item := range items {
+		if !hreflangRegexp.MatchString(item.Lang) {
+			return nil, fmt.Errorf("hreflang: item %d: invalid lang %q", i, item.Lang)
+		}
+
+		if err = validateHref(item.Href); err != nil {
+			return nil, fmt.Errorf("hreflang: item %d: %w", i, err)
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,111 @@
+package generation
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_decodeFaq(t *testing.T) {
+	t.Run("should decode empty values", func(t *testing.T) {
+		for _, raw := range []string{"", "null", "{}", "[]", `{"items": []}`} {
+			items, err := decodeFaq([]byte(raw))
+			require.Nil(t, err, raw)
+			require.Empty(t, items, raw)
+		}
+	})
+
+	t.Run("should decode array and object formats sorted by order", func(t *testing.T) {
+		expected := []radixtrie.FaqItem{
+			{Question: "q1", Answer: "a1", Order: 1},
+			{Question: "q2", Answer: "a2", Order: 2},
+			{Question: "q3", Answer: "a3", Order: 2},
+		}
+
+		items, err := decodeFaq([]byte(`[
+			{"question": "q2", "answer": "a2", "order": 2},
+			{"question": "q1", "answer": "a1", "order": 1},
+			{"question": "q3", "answer": "a3", "order": 2}
+		]`))
+		require.Nil(t, err)
+		require.Equal(t, expected, items)
+
+		items, err = decodeFaq([]byte(`{"items": [
+			{"question": "q2", "answer": "a2", "order": 2},
+			{"question": "q1", "answer": "a1", "order": 1},
+			{"question": "q3", "answer": "a3", "order": 2}
+		]}`))
+		require.Nil(t, err)
+		require.Equal(t, expected, items)
+	})
+
+	t.Run("should reject invalid faq", func(t *testing.T) {
+		_, err := decodeFaq([]byte(`[{"question": "q"}]`))
+		require.ErrorContains(t, err, "faq: item 0: answer is required")
+
+		_, err = decodeFaq([]byte(`[{"question": " ", "answer": "a"}]`))
+		require.ErrorContains(t, err, "faq: item 0: question is required")
+
+		_, err = decodeFaq([]byte(`{"questions": []}`))
+		require.ErrorContains(t, err, "faq:")
+
+		_, err = decodeFaq([]byte(`"text"`))
+		require.ErrorContains(t, err, "faq:")
+	})
+}
+
+func Test_decodeTagsCloud(t *testing.T) {
+	t.Run("should decode tags cloud", func(t *testing.T) {
+		items, err := decodeTagsCloud([]byte(`[
+			{"label": "Sneakers", "href": "/catalog/sneakers", "weight": 1.5},
+			{"label": "Brand", "href": "https://example.ru/brand"}
+		]`))
+		require.Nil(t, err)
+		require.Equal(t, []radixtrie.TagsCloudItem{
+			{Label: "Sneakers", Href: "/catalog/sneakers", Weight: 1.5},
+			{Label: "Brand", Href: "https://example.ru/brand"},
+		}, items)
+	})
+
+	t.Run("should reject invalid tags cloud", func(t *testing.T) {
+		_, err := decodeTagsCloud([]byte(`[{"href": "/a"}]`))
+		require.ErrorContains(t, err, "tags_cloud: item 0: label is required")
+
+		_, err = decodeTagsCloud([]byte(`[{"label": "a", "href": "javascript:alert(1)"}]`))
+		require.ErrorContains(t, err, "must be a path or an absolute http(s) url")
+
+		_, err = decodeTagsCloud([]byte(`[{"label": "a", "href": "//evil.com"}]`))
+		require.ErrorContains(t, err, "must not be protocol-relative")
+
+		_, err = decodeTagsCloud([]byte(`[{"label": "a", "href": "/a", "weight": -1}]`))
+		require.ErrorContains(t, err, "weight must be a non-negative number")
+	})
+}
+
+func Test_decodeHreflang(t *testing.T) {
+	t.Run("should decode hreflang alternates", func(t *testing.T) {
+		items, err := decodeHreflang([]byte(`[
+			{"lang": "en", "href": "/en/catalog"},
+			{"lang": "ru-RU", "href": "https://example.ru/catalog"},
+			{"lang": "x-default", "href": "/catalog"}
+		]`))
+		require.Nil(t, err)
+		require.Equal(t, []radixtrie.HreflangAlternate{
+			{Lang: "en", Href: "/en/catalog"},
+			{Lang: "ru-RU", Href: "https://example.ru/catalog"},
+			{Lang: "x-default", Href: "/catalog"},
+		}, items)
+
+		items, err = decodeHreflang([]byte(`{}`))
+		require.Nil(t, err)
+		require.Empty(t, items)
+	})
+
+	t.Run("should reject invalid hreflang", func(t *testing.T) {
+		_, err := decodeHreflang([]byte(`[{"lang": "english", "href": "/en"}]`))
+		require.ErrorContains(t, err, `hreflang: item 0: invalid lang "english"`)
+
+		_, err = decodeHreflang([]byte(`[{"lang": "en"}]`))
+		require.ErrorContains(t, err, "hreflang: item 0: href is required")
+	})
+}
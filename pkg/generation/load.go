@@ -0,0 +1,70 @@
+package generation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/quadgod/seo/pkg/urlnorm"
+	"time"
+)
+
+// Load построчно читает seo_declarations и seo_redirects указанной генерации и строит из них деревья сайтов:
+// по дереву на каждое значение колонки host, строки с пустым host попадают в дерево сайта по умолчанию.
+// Строки одного url с разными locale попадают в один узел дерева (см. radixtrie.Trie.InsertLocale).
+// Строки с невалидным хостом, url, шаблоном или jsonb колонками не прерывают загрузку, а попадают в Stats.Rejected.
+// Строки, url которых совпал с уже загруженным в дерево того же сайта (строки читаются в порядке host, url),
+// и уже имеет данные той же локали, попадают в Stats.Conflicts.
+// Если задан normalizer, url нормализуются перед вставкой, поэтому конфликтовать могут и разные в базе url.
+// Редиректы вставляются после деклараций, зацикленные редиректы попадают в Stats.Rejected
+func Load(ctx context.Context, q Querier, generation time.Time, normalizer *urlnorm.Normalizer) (*site.Sites, *Stats, error) {
+	startedAt := time.Now()
+	stats := &Stats{Generation: generation, Rejected: make([]Rejected, 0), Conflicts: make([]Conflict, 0)}
+	sites := site.New(radixtrie.NewTrie())
+
+	rows, err := q.Query(ctx, selectDeclarationsSql, generation)
+	if err != nil {
+		return nil, nil, fmt.Errorf("select seo declarations errors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d := newDeclaration()
+		if err = rows.Scan(d.targets()...); err != nil {
+			return nil, nil, fmt.Errorf("scan seo declaration errors: %w", err)
+		}
+
+		stats.Rows++
+
+		if err = d.decode(normalizer); err != nil {
+			stats.reject(Rejected{Host: d.host, URL: d.url, Locale: d.locale}, err)
+			continue
+		}
+
+		if err = sites.Add(d.host).InsertLocale(d.pattern, d.locale, d.data); err != nil {
+			var conflict *radixtrie.ConflictError
+			if !errors.As(err, &conflict) {
+				return nil, nil, fmt.Errorf("insert seo declaration %q errors: %w", d.url, err)
+			}
+
+			stats.Conflicts = append(stats.Conflicts, Conflict{Host: d.host, URL: d.url, Locale: d.locale, Existing: conflict.Existing})
+			continue
+		}
+
+		stats.Inserted++
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read seo declarations errors: %w", err)
+	}
+
+	if err = loadRedirects(ctx, q, generation, normalizer, sites, stats); err != nil {
+		return nil, nil, err
+	}
+
+	stats.Sites = len(sites.Hosts())
+	stats.Duration = time.Since(startedAt)
+
+	return sites, stats, nil
+}
@@ -0,0 +1,261 @@
+package generation
+
+import (
+	"context"
+	"github.com/quadgod/seo/pkg/pgm/db"
+	"github.com/quadgod/seo/pkg/pgtest"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/urlnorm"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_Load(t *testing.T) {
+	ctx := context.Background()
+	connStr := pgtest.Run(t, "../../migrations")
+
+	pool, err := db.Connect(ctx, connStr)
+	require.Nil(t, err)
+	defer pool.Close()
+
+	generation := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	otherGeneration := generation.Add(time.Hour)
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO seo_declarations (generation, url, meta_title, meta_description, meta_robots, meta_keywords, faq, tags_cloud)
+		VALUES
+			($1, '/', 'main', null, 'index, follow', null,
+				'[{"question": "second", "answer": "a2", "order": 2}, {"question": "first", "answer": "a1", "order": 1}]', '{}'),
+			($1, '/catalog/:slug', 'conflict', null, null, null, '{}', '{}'),
+			($1, '/catalog/:category', 'category', 'category description', null, 'shoes',
+				'{}', '{"items": [{"label": "Sneakers", "href": "/catalog/sneakers", "weight": 2}]}'),
+			($1, '/catalog/:category/*rest', '{{category}}: {{rest}}', null, null, null, '{}', '{}'),
+			($1, '/brand/:brand', '{{category}}', null, null, null, '{}', '{}'),
+			($1, '/faq', 'faq', null, null, null, '[{"question": "", "answer": "a"}]', '{}'),
+			($1, '/hreflang', 'hreflang', null, null, null, '{}', '{}'),
+			($1, '/broken/*rest/tail', 'broken', null, null, null, '{}', '{}'),
+			($1, 'no-slash', 'broken', null, null, null, '{}', '{}'),
+			($2, '/other', 'other', null, null, null, '{}', '{}');
+	`, generation, otherGeneration)
+	require.Nil(t, err)
+
+	_, err = pool.Exec(ctx, `
+		UPDATE seo_declarations
+		SET meta_header = '{{category}}', canonical_link = '/catalog/{{category}}',
+			og_title = 'og {{category}}', og_description = 'og description', og_image = '/og.png', og_type = 'website',
+			hreflang = '[{"lang": "en", "href": "/en/catalog/{{category}}"}]'
+		WHERE generation = $1 AND url = '/catalog/:category';
+	`, generation)
+	require.Nil(t, err)
+
+	_, err = pool.Exec(ctx, `
+		UPDATE seo_declarations SET hreflang = '[{"lang": "english", "href": "/en"}]'
+		WHERE generation = $1 AND url = '/hreflang';
+	`, generation)
+	require.Nil(t, err)
+
+	t.Run("should load only declarations of requested generation", func(t *testing.T) {
+		sites, stats, err := Load(ctx, pool, generation, nil)
+		require.Nil(t, err)
+		trie := sites.Default()
+
+		require.Equal(t, generation, stats.Generation)
+		require.Equal(t, 9, stats.Rows)
+		require.Equal(t, 3, stats.Inserted)
+		require.Len(t, stats.Rejected, 5)
+		require.Equal(t, []Conflict{{URL: "/catalog/:slug", Existing: "/catalog/:category"}}, stats.Conflicts)
+		require.Nil(t, trie.Search("/other", new(radixtrie.Params)))
+
+		params := new(radixtrie.Params)
+		n := trie.Search("/catalog/shoes", params)
+		require.NotNil(t, n)
+		require.Equal(t, "/catalog/:category", n.String())
+		require.Equal(t, "shoes", params.Get("category"))
+		require.Equal(t, "category", *n.Data.MetaTitle)
+		require.Equal(t, "category description", *n.Data.MetaDescription)
+		require.Nil(t, n.Data.MetaRobots)
+		require.Nil(t, n.Data.Faq)
+
+		rendered := n.Data.Render(params)
+		require.Equal(t, "shoes", *rendered.MetaHeader)
+		require.Equal(t, "/catalog/shoes", *rendered.CanonicalLink)
+		require.Equal(t, "og shoes", *rendered.OgTitle)
+		require.Equal(t, "og description", *rendered.OgDescription)
+		require.Equal(t, "/og.png", *rendered.OgImage)
+		require.Equal(t, "website", *rendered.OgType)
+		require.Equal(t, []radixtrie.HreflangAlternate{{Lang: "en", Href: "/en/catalog/shoes"}}, rendered.Hreflang)
+		require.Equal(t, []radixtrie.TagsCloudItem{{Label: "Sneakers", Href: "/catalog/sneakers", Weight: 2}}, n.Data.TagsCloud)
+
+		restParams := new(radixtrie.Params)
+		rest := trie.Search("/catalog/shoes/red/42", restParams)
+		require.NotNil(t, rest)
+		require.Equal(t, "shoes: red/42", *rest.Data.Render(restParams).MetaTitle)
+
+		root := trie.Search("/", new(radixtrie.Params))
+		require.NotNil(t, root)
+		require.Equal(t, "index, follow", *root.Data.MetaRobots)
+		require.Equal(t, []radixtrie.FaqItem{
+			{Question: "first", Answer: "a1", Order: 1},
+			{Question: "second", Answer: "a2", Order: 2},
+		}, root.Data.Faq)
+	})
+
+	t.Run("should check generation exists", func(t *testing.T) {
+		exists, err := Exists(ctx, pool, generation)
+		require.Nil(t, err)
+		require.True(t, exists)
+
+		exists, err = Exists(ctx, pool, generation.Add(-time.Hour))
+		require.Nil(t, err)
+		require.False(t, exists)
+	})
+
+	t.Run("should return empty trie for unknown generation", func(t *testing.T) {
+		sites, stats, err := Load(ctx, pool, generation.Add(-time.Hour), nil)
+		require.Nil(t, err)
+		trie := sites.Default()
+		require.Equal(t, 0, stats.Rows)
+		require.Nil(t, trie.Search("/", new(radixtrie.Params)))
+	})
+
+	t.Run("should insert normalized urls", func(t *testing.T) {
+		normalizedGeneration := generation.Add(2 * time.Hour)
+		_, err := pool.Exec(ctx, `
+			INSERT INTO seo_declarations (generation, url, meta_title, faq, tags_cloud)
+			VALUES
+				($1, '/Catalog//:category/', 'category', '{}', '{}'),
+				($1, '/catalog/:category', 'duplicate', '{}', '{}'),
+				($1, '/%D0%9E%D0%B1%D1%83%D0%B2%D1%8C', 'cyrillic', '{}', '{}'),
+				($1, '/broken%zz', 'broken', '{}', '{}');
+		`, normalizedGeneration)
+		require.Nil(t, err)
+
+		sites, stats, err := Load(ctx, pool, normalizedGeneration, urlnorm.New(urlnorm.DefaultOptions))
+		require.Nil(t, err)
+		trie := sites.Default()
+		require.Equal(t, 2, stats.Inserted)
+		require.Len(t, stats.Rejected, 1)
+		// какая из двух строк попадет в конфликт, зависит от collation сортировки url
+		require.Len(t, stats.Conflicts, 1)
+		require.Equal(t, "/catalog/:category", stats.Conflicts[0].Existing)
+
+		require.NotNil(t, trie.Search("/catalog/shoes", new(radixtrie.Params)))
+		require.NotNil(t, trie.Search("/обувь", new(radixtrie.Params)))
+	})
+
+	t.Run("should load redirects and reject loops", func(t *testing.T) {
+		redirectsGeneration := generation.Add(3 * time.Hour)
+		_, err := pool.Exec(ctx, `
+			INSERT INTO seo_declarations (generation, url, meta_title, faq, tags_cloud)
+			VALUES ($1, '/catalog/:category', 'category', '{}', '{}');
+		`, redirectsGeneration)
+		require.Nil(t, err)
+
+		_, err = pool.Exec(ctx, `
+			INSERT INTO seo_redirects (generation, source, target, status_code)
+			VALUES
+				($1, '/old/:category', '/catalog/{{category}}', 301),
+				($1, '/moved', 'https://example.ru/moved', 302),
+				($1, '/catalog/:slug', '/new', 301),
+				($1, '/loop/a', '/loop/b', 301),
+				($1, '/loop/b', '/loop/a', 301),
+				($1, '/broken', '/{{slug}}', 301);
+		`, redirectsGeneration)
+		require.Nil(t, err)
+
+		sites, stats, err := Load(ctx, pool, redirectsGeneration, urlnorm.New(urlnorm.DefaultOptions))
+		require.Nil(t, err)
+		trie := sites.Default()
+		require.Equal(t, 7, stats.Rows)
+		require.Equal(t, 3, stats.Inserted)
+		require.Equal(t, 2, stats.Redirects)
+		require.Equal(t, []Conflict{{URL: "/catalog/:slug", Existing: "/catalog/:category"}}, stats.Conflicts)
+		require.Len(t, stats.Rejected, 2)
+		require.Equal(t, "/broken", stats.Rejected[0].URL)
+		require.Equal(t, "/loop/a", stats.Rejected[1].URL)
+
+		params := new(radixtrie.Params)
+		n := trie.Search("/old/shoes", params)
+		require.NotNil(t, n)
+		require.Equal(t, "/catalog/shoes", n.Redirect.Location(params))
+		require.Equal(t, 301, n.Redirect.Status)
+
+		require.Equal(t, 302, trie.Search("/moved", new(radixtrie.Params)).Redirect.Status)
+		require.NotNil(t, trie.Search("/loop/b", new(radixtrie.Params)))
+		require.Nil(t, trie.Search("/loop/a", new(radixtrie.Params)))
+	})
+
+	t.Run("should load tries per host", func(t *testing.T) {
+		hostsGeneration := generation.Add(4 * time.Hour)
+		_, err := pool.Exec(ctx, `
+			INSERT INTO seo_declarations (generation, host, url, meta_title, faq, tags_cloud)
+			VALUES
+				($1, '', '/catalog/:category', 'default', '{}', '{}'),
+				($1, '', '/about', 'about', '{}', '{}'),
+				($1, 'Shop.Example.ru', '/catalog/:category', 'shop', '{}', '{}'),
+				($1, 'shop.example.ru', '/catalog/:slug', 'conflict', '{}', '{}'),
+				($1, '*.example.ru', '/promo', 'promo', '{}', '{}'),
+				($1, 'a.*.ru', '/broken', 'broken', '{}', '{}');
+		`, hostsGeneration)
+		require.Nil(t, err)
+
+		_, err = pool.Exec(ctx, `
+			INSERT INTO seo_redirects (generation, host, source, target, status_code)
+			VALUES ($1, 'shop.example.ru', '/old', '/promo', 301);
+		`, hostsGeneration)
+		require.Nil(t, err)
+
+		sites, stats, err := Load(ctx, pool, hostsGeneration, urlnorm.New(urlnorm.DefaultOptions))
+		require.Nil(t, err)
+		require.Equal(t, 3, stats.Sites)
+		require.Equal(t, 5, stats.Inserted)
+		require.Len(t, stats.Rejected, 1)
+		require.Equal(t, "a.*.ru", stats.Rejected[0].Host)
+		require.Equal(t, []Conflict{{Host: "shop.example.ru", URL: "/catalog/:slug", Existing: "/catalog/:category"}}, stats.Conflicts)
+
+		n, host := sites.Search("shop.example.ru", "/catalog/shoes", new(radixtrie.Params))
+		require.Equal(t, "shop", *n.Data.MetaTitle)
+		require.Equal(t, "shop.example.ru", host)
+
+		n, host = sites.Search("shop.example.ru", "/promo", new(radixtrie.Params))
+		require.Equal(t, "promo", *n.Data.MetaTitle)
+		require.Equal(t, "*.example.ru", host)
+
+		n, host = sites.Search("other.ru", "/catalog/shoes", new(radixtrie.Params))
+		require.Equal(t, "default", *n.Data.MetaTitle)
+		require.Equal(t, "", host)
+
+		n, _ = sites.Search("shop.example.ru", "/old", new(radixtrie.Params))
+		require.Equal(t, "/promo", n.Redirect.Target)
+	})
+
+	t.Run("should load localized declarations into one node", func(t *testing.T) {
+		localesGeneration := generation.Add(5 * time.Hour)
+		_, err := pool.Exec(ctx, `
+			INSERT INTO seo_declarations (generation, url, locale, meta_title, faq, tags_cloud)
+			VALUES
+				($1, '/catalog/:category', '', 'каталог', '{}', '{}'),
+				($1, '/catalog/:category', 'EN', 'catalog', '{}', '{}'),
+				($1, '/catalog/:category', 'en_GB', 'catalogue', '{}', '{}'),
+				($1, '/catalog/:slug', 'de', 'konflikt', '{}', '{}'),
+				($1, '/about', 'english', 'broken', '{}', '{}');
+		`, localesGeneration)
+		require.Nil(t, err)
+
+		sites, stats, err := Load(ctx, pool, localesGeneration, urlnorm.New(urlnorm.DefaultOptions))
+		require.Nil(t, err)
+		require.Equal(t, 3, stats.Inserted)
+		require.Equal(t, []Conflict{{URL: "/catalog/:slug", Locale: "de", Existing: "/catalog/:category"}}, stats.Conflicts)
+		require.Len(t, stats.Rejected, 1)
+		require.Equal(t, "english", stats.Rejected[0].Locale)
+
+		n := sites.Default().Search("/catalog/shoes", new(radixtrie.Params))
+		require.Equal(t, []string{"en", "en-gb"}, n.Locales())
+		require.Equal(t, "каталог", *n.Data.MetaTitle)
+
+		data, locale := n.LocaleData([]string{"en-gb", "en", ""})
+		require.Equal(t, "catalogue", *data.MetaTitle)
+		require.Equal(t, "en-gb", locale)
+	})
+}
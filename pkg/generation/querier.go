@@ -0,0 +1,12 @@
+package generation
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5"
+)
+
+// Querier выполняет запросы к базе данных. Ему удовлетворяют *pgxpool.Pool, *pgx.Conn и pgx.Tx
+type Querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
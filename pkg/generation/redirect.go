@@ -0,0 +1,194 @@
+package generation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/quadgod/seo/pkg/urlnorm"
+	"slices"
+	"strings"
+	"time"
+)
+
+const selectRedirectsSql = `
+	SELECT host, source, target, status_code
+	FROM "public"."seo_redirects"
+	WHERE generation = $1
+	ORDER BY host, source;
+`
+
+// redirectStatuses допустимые http коды редиректов
+var redirectStatuses = []int{301, 302, 307, 308}
+
+// redirect строка seo_redirects
+type redirect struct {
+	host    string // хост или шаблон хоста сайта, после decode - приведенный site.ParseHost
+	source  string
+	pattern string // нормализованный source, с которым редирект вставляется в дерево
+	data    *radixtrie.Redirect
+}
+
+func newRedirect() *redirect {
+	return &redirect{data: new(radixtrie.Redirect)}
+}
+
+func (r *redirect) targets() []any {
+	return []any{&r.host, &r.source, &r.data.Target, &r.data.Status}
+}
+
+// decode нормализует и валидирует хост и source, валидирует target и status_code и компилирует target
+func (r *redirect) decode(normalizer *urlnorm.Normalizer) (err error) {
+	if r.host, err = site.ParseHost(r.host); err != nil {
+		return err
+	}
+
+	if r.pattern, err = normalizer.Pattern(r.source); err != nil {
+		return fmt.Errorf("normalize source: %w", err)
+	}
+
+	if err = radixtrie.ValidatePattern(r.pattern); err != nil {
+		return err
+	}
+
+	if err = validateHref(r.data.Target); err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	if !slices.Contains(redirectStatuses, r.data.Status) {
+		return fmt.Errorf("invalid redirect status %d", r.data.Status)
+	}
+
+	if err = r.data.Compile(radixtrie.ParamKeys(r.pattern)); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	return nil
+}
+
+// loadRedirects читает seo_redirects генерации и вставляет их в деревья сайтов рядом с seo декларациями.
+// Невалидные и зацикленные редиректы попадают в Stats.Rejected, совпавшие с уже загруженными url - в Stats.Conflicts
+func loadRedirects(
+	ctx context.Context,
+	q Querier,
+	generation time.Time,
+	normalizer *urlnorm.Normalizer,
+	sites *site.Sites,
+	stats *Stats,
+) error {
+	rows, err := q.Query(ctx, selectRedirectsSql, generation)
+	if err != nil {
+		return fmt.Errorf("select seo redirects errors: %w", err)
+	}
+	defer rows.Close()
+
+	inserted := make([]*redirect, 0)
+	for rows.Next() {
+		r := newRedirect()
+		if err = rows.Scan(r.targets()...); err != nil {
+			return fmt.Errorf("scan seo redirect errors: %w", err)
+		}
+
+		stats.Rows++
+
+		if err = r.decode(normalizer); err != nil {
+			stats.reject(Rejected{Host: r.host, URL: r.source}, err)
+			continue
+		}
+
+		if err = sites.Add(r.host).Insert(r.pattern, radixtrie.WithRedirect(r.data)); err != nil {
+			var conflict *radixtrie.ConflictError
+			if !errors.As(err, &conflict) {
+				return fmt.Errorf("insert seo redirect %q errors: %w", r.source, err)
+			}
+
+			stats.Conflicts = append(stats.Conflicts, Conflict{Host: r.host, URL: r.source, Existing: conflict.Existing})
+			continue
+		}
+
+		inserted = append(inserted, r)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("read seo redirects errors: %w", err)
+	}
+
+	// url цепочек ищутся так же, как их будет искать сервер, см. urlnorm.Options.Lowercase
+	for _, host := range sites.Hosts() {
+		sites.Trie(host).SetCaseFolding(normalizer.Options().Lowercase)
+	}
+
+	for _, r := range inserted {
+		if loop := redirectLoop(hostSearcher{sites: sites, host: r.host}, normalizer, r.pattern); loop != nil {
+			sites.Trie(r.host).Delete(r.pattern)
+			stats.reject(Rejected{Host: r.host, URL: r.source}, fmt.Errorf("redirect loop: %s", strings.Join(loop, " -> ")))
+			continue
+		}
+
+		stats.Inserted++
+		stats.Redirects++
+	}
+
+	return nil
+}
+
+// searcher дерево или цепочка деревьев сайта, в которых ищутся url цепочки редиректов
+type searcher interface {
+	Search(url string, params radixtrie.ParamsSetter) *radixtrie.Node
+}
+
+// hostSearcher ищет url так же, как сервер для запросов хоста: в дереве хоста,
+// деревьях подходящих шаблонов и дереве сайта по умолчанию
+type hostSearcher struct {
+	sites *site.Sites
+	host  string
+}
+
+func (h hostSearcher) Search(url string, params radixtrie.ParamsSetter) *radixtrie.Node {
+	n, _ := h.sites.Search(h.host, url, params)
+	return n
+}
+
+// redirectLoop проходит по цепочке редиректов, начиная с url, подходящего под pattern
+// (см. radixtrie.SampleURL), и возвращает цепочку шаблонов, если она возвращается к pattern.
+// Цепочка обрывается на url без редиректа и на абсолютных target, которые считаются внешними
+func redirectLoop(trie searcher, normalizer *urlnorm.Normalizer, pattern string) []string {
+	url, ok := radixtrie.SampleURL(pattern)
+	if !ok {
+		return nil
+	}
+
+	chain := make([]string, 0)
+	for {
+		params := new(radixtrie.Params)
+		n := trie.Search(url, params)
+		if n == nil || n.Redirect == nil {
+			return nil
+		}
+
+		if len(chain) == 0 && n.String() != pattern {
+			// под пример url попал более конкретный шаблон, проверить pattern не получится
+			return nil
+		}
+
+		if slices.Contains(chain, n.String()) {
+			if n.String() != pattern {
+				// цикл без pattern найдет проверка одного из его редиректов
+				return nil
+			}
+			return append(chain, n.String())
+		}
+		chain = append(chain, n.String())
+
+		target := n.Redirect.Location(params)
+		if !strings.HasPrefix(target, "/") {
+			return nil
+		}
+
+		var err error
+		if url, err = normalizer.URL(target); err != nil {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package generation
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/urlnorm"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_redirectDecode(t *testing.T) {
+	normalizer := urlnorm.New(urlnorm.DefaultOptions)
+
+	t.Run("should normalize source and compile target", func(t *testing.T) {
+		r := newRedirect()
+		r.source, r.data.Target, r.data.Status = "/Old//:category/", "/catalog/{{category}}", 301
+		require.Nil(t, r.decode(normalizer))
+		require.Equal(t, "/old/:category", r.pattern)
+	})
+
+	t.Run("should normalize host", func(t *testing.T) {
+		r := newRedirect()
+		r.host, r.source, r.data.Target, r.data.Status = "Shop.Example.ru", "/old", "/new", 301
+		require.Nil(t, r.decode(normalizer))
+		require.Equal(t, "shop.example.ru", r.host)
+
+		r.host = "a.*.ru"
+		require.NotNil(t, r.decode(normalizer))
+	})
+
+	t.Run("should reject invalid rows", func(t *testing.T) {
+		for _, row := range []struct {
+			source, target string
+			status         int
+		}{
+			{"old", "/new", 301},
+			{"/old", "", 301},
+			{"/old", "//example.ru/new", 301},
+			{"/old", "/new", 200},
+			{"/old/:id", "/new/{{slug}}", 301},
+		} {
+			r := newRedirect()
+			r.source, r.data.Target, r.data.Status = row.source, row.target, row.status
+			require.NotNil(t, r.decode(normalizer), row)
+		}
+	})
+}
+
+func Test_redirectLoop(t *testing.T) {
+	normalizer := urlnorm.New(urlnorm.DefaultOptions)
+
+	trie := radixtrie.NewTrie()
+	trie.SetCaseFolding(true)
+	insert := func(source, target string) {
+		r := &radixtrie.Redirect{Target: target, Status: 301}
+		require.Nil(t, r.Compile(radixtrie.ParamKeys(source)))
+		require.Nil(t, trie.Insert(source, radixtrie.WithRedirect(r)))
+	}
+
+	insert("/a", "/b")
+	insert("/b", "/c")
+	insert("/c", "/a")
+	insert("/to-loop", "/a")
+	insert("/self/:slug", "/self/{{slug}}")
+	insert("/grow/*rest", "/grow/x/{{rest}}")
+	insert("/old/:slug", "/new/{{slug}}")
+	insert("/new/:slug", "https://example.ru/old/{{slug}}")
+	insert("/chain", "/Old/page/")
+	insert("/upper/:slug", "/UPPER/{{slug}}")
+	require.Nil(t, trie.Insert("/page", radixtrie.WithData(&radixtrie.SeoData{})))
+
+	require.Equal(t, []string{"/a", "/b", "/c", "/a"}, redirectLoop(trie, normalizer, "/a"))
+	require.Equal(t, []string{"/self/:slug", "/self/:slug"}, redirectLoop(trie, normalizer, "/self/:slug"))
+	require.Equal(t, []string{"/grow/*rest", "/grow/*rest"}, redirectLoop(trie, normalizer, "/grow/*rest"))
+	require.Nil(t, redirectLoop(trie, normalizer, "/to-loop"))
+	require.Nil(t, redirectLoop(trie, normalizer, "/old/:slug"))
+	require.Nil(t, redirectLoop(trie, normalizer, "/chain"))
+	require.Equal(t, []string{"/upper/:slug", "/upper/:slug"}, redirectLoop(trie, normalizer, "/upper/:slug"))
+}
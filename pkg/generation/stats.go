@@ -0,0 +1,43 @@
+package generation
+
+import (
+	"time"
+)
+
+// Rejected описывает строку seo_declarations или seo_redirects, которая не попала в дерево
+type Rejected struct {
+	Host   string `json:"host,omitempty"`
+	URL    string `json:"url"`
+	Locale string `json:"locale,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Conflict описывает строку seo_declarations, url которой совпал с уже загруженным
+// в дереве того же сайта с точностью до имен параметров (например, /a/:id и /a/:slug)
+// и у которого уже есть данные той же локали
+type Conflict struct {
+	Host     string `json:"host,omitempty"`
+	URL      string `json:"url"`
+	Locale   string `json:"locale,omitempty"`
+	Existing string `json:"existing"`
+}
+
+// Stats статистика загрузки генерации.
+// Rows и Inserted учитывают и seo_declarations, и seo_redirects, Redirects - только вставленные редиректы,
+// Sites - количество деревьев сайтов, включая сайт по умолчанию
+type Stats struct {
+	Generation time.Time     `json:"generation"`
+	Rows       int           `json:"rows"`
+	Inserted   int           `json:"inserted"`
+	Redirects  int           `json:"redirects"`
+	Sites      int           `json:"sites"`
+	Duration   time.Duration `json:"duration"`
+	Rejected   []Rejected    `json:"rejected"`
+	Conflicts  []Conflict    `json:"conflicts"`
+}
+
+// reject добавляет строку в Rejected с причиной err
+func (s *Stats) reject(row Rejected, err error) {
+	row.Reason = err.Error()
+	s.Rejected = append(s.Rejected, row)
+}
@@ -0,0 +1,92 @@
+package locale
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Neutral локаль данных без языка (пустая колонка locale), последняя в любой цепочке
+const Neutral = ""
+
+// Parse приводит тег локали к виду, в котором он хранится в дереве: нижний регистр, "-" вместо "_" (en_GB -> en-gb).
+// Тег - язык из 2-3 букв и необязательные подтеги из 1-8 букв и цифр. Пустой тег - Neutral
+func Parse(raw string) (string, error) {
+	tag := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(raw)), "_", "-")
+	if tag == Neutral {
+		return Neutral, nil
+	}
+
+	for i, sub := range strings.Split(tag, "-") {
+		if i == 0 && (len(sub) < 2 || len(sub) > 3 || !isAlnum(sub, false)) || i > 0 && (sub == "" || len(sub) > 8 || !isAlnum(sub, true)) {
+			return "", fmt.Errorf("invalid locale %q", raw)
+		}
+	}
+
+	return tag, nil
+}
+
+// Parents возвращает тег и его родительские теги от более конкретного к общему: en-gb -> [en-gb, en]
+func Parents(tag string) []string {
+	parents := []string{tag}
+	for {
+		i := strings.LastIndexByte(tag, '-')
+		if i == -1 {
+			return parents
+		}
+
+		tag = tag[:i]
+		parents = append(parents, tag)
+	}
+}
+
+// AcceptLanguage разбирает заголовок Accept-Language и возвращает теги в порядке убывания q.
+// Теги с q=0, "*" и невалидные теги пропускаются
+func AcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	tags := make([]weighted, 0)
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		tag, err := Parse(tag)
+		if err != nil || tag == Neutral || q <= 0 {
+			continue
+		}
+
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+
+	return result
+}
+
+func isAlnum(s string, digits bool) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('a' <= c && c <= 'z' || digits && '0' <= c && c <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,73 @@
+package locale
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_Parse(t *testing.T) {
+	for raw, expected := range map[string]string{"": Neutral, "EN": "en", "en_GB": "en-gb", "zh-Hant-TW": "zh-hant-tw"} {
+		actual, err := Parse(raw)
+		require.Nil(t, err, raw)
+		require.Equal(t, expected, actual, raw)
+	}
+
+	for _, raw := range []string{"e", "english", "en-", "e1", "en-gb!", "*"} {
+		_, err := Parse(raw)
+		require.NotNil(t, err, raw)
+	}
+}
+
+func Test_AcceptLanguage(t *testing.T) {
+	require.Equal(t, []string{"en-gb", "en", "ru"}, AcceptLanguage("ru;q=0.5, en-GB, en;q=0.8, *;q=0.1, de;q=0"))
+	require.Equal(t, []string{}, AcceptLanguage(""))
+}
+
+func Test_Resolve(t *testing.T) {
+	r := &Resolver{Default: "ru", Prefixes: []string{"en", "de"}}
+
+	t.Run("should build chain from explicit locale, prefix and accept-language", func(t *testing.T) {
+		m, err := r.Resolve("en-US", "de-AT, en;q=0.5", "/en/catalog/shoes?x=1")
+		require.Nil(t, err)
+		require.Equal(t, []string{"en-us", "en", "de-at", "de", "ru", Neutral}, m.Chain)
+		require.Equal(t, "en", m.Prefix)
+		require.Equal(t, "/catalog/shoes?x=1", m.URL)
+	})
+
+	t.Run("should cut prefix of root url", func(t *testing.T) {
+		for _, url := range []string{"/en", "/en/"} {
+			m, err := r.Resolve("", "", url)
+			require.Nil(t, err)
+			require.Equal(t, "/", m.URL, url)
+			require.Equal(t, []string{"en", "ru", Neutral}, m.Chain, url)
+		}
+
+		m, _ := r.Resolve("", "", "/en?x=1")
+		require.Equal(t, "/?x=1", m.URL)
+
+		m, _ = r.Resolve("", "", "/english")
+		require.Equal(t, "/english", m.URL)
+		require.Equal(t, []string{"ru", Neutral}, m.Chain)
+	})
+
+	t.Run("should reject invalid explicit locale", func(t *testing.T) {
+		_, err := r.Resolve("english", "", "/")
+		require.NotNil(t, err)
+	})
+
+	t.Run("should build href of locale", func(t *testing.T) {
+		require.Equal(t, "/en/catalog", r.Href("en", "/catalog"))
+		require.Equal(t, "/en", r.Href("en", "/"))
+		require.Equal(t, "/en?x=1", r.Href("en", "/?x=1"))
+		require.Equal(t, "/catalog", r.Href("ru", "/catalog"))
+	})
+
+	t.Run("should work without resolver", func(t *testing.T) {
+		var empty *Resolver
+		m, err := empty.Resolve("", "en", "/en/catalog")
+		require.Nil(t, err)
+		require.Equal(t, []string{"en", Neutral}, m.Chain)
+		require.Equal(t, "/en/catalog", m.URL)
+		require.Equal(t, Neutral, empty.DefaultLocale())
+	})
+}
@@ -0,0 +1,116 @@
+package locale
+
+import (
+	"slices"
+	"strings"
+)
+
+// Resolver определяет локаль запроса. Методы Resolver можно вызывать и для nil,
+// тогда префиксов и локали по умолчанию нет
+type Resolver struct {
+	// Default локаль сайта: данные Neutral отдаются как данные этой локали
+	Default string
+	// Prefixes локали, url которых начинаются с префикса локали: /en/catalog/shoes
+	Prefixes []string
+}
+
+// Match локали запроса
+type Match struct {
+	// Chain локали в порядке предпочтения вместе с родительскими, заканчивается Default и Neutral
+	Chain []string
+	// Prefix локаль из префикса url, если url начинался с него
+	Prefix string
+	// URL url запроса без префикса локали
+	URL string
+}
+
+// Resolve строит цепочку локалей запроса: явно переданная локаль, локаль префикса url,
+// локали Accept-Language, Default и Neutral. Каждая локаль идет вместе с родительскими (en-gb, en).
+// url должен быть нормализован, префикс локали отрезается от него
+func (r *Resolver) Resolve(explicit, acceptLanguage, url string) (Match, error) {
+	m := Match{URL: url, Chain: make([]string, 0, 4)}
+
+	explicit, err := Parse(explicit)
+	if err != nil {
+		return Match{}, err
+	}
+	m.add(explicit)
+
+	if m.Prefix, m.URL = r.cutPrefix(url); m.Prefix != Neutral {
+		m.add(m.Prefix)
+	}
+
+	for _, tag := range AcceptLanguage(acceptLanguage) {
+		m.add(tag)
+	}
+
+	if r != nil {
+		m.add(r.Default)
+	}
+	m.Chain = append(m.Chain, Neutral)
+
+	return m, nil
+}
+
+// Href возвращает url страницы для локали: с префиксом, если локаль из Prefixes, иначе url как есть
+func (r *Resolver) Href(locale, url string) string {
+	if r == nil || locale == Neutral || !slices.Contains(r.Prefixes, locale) {
+		return url
+	}
+
+	if url == "/" {
+		return "/" + locale
+	}
+
+	if strings.HasPrefix(url, "/?") {
+		return "/" + locale + url[1:]
+	}
+
+	return "/" + locale + url
+}
+
+// DefaultLocale возвращает Default или Neutral для nil
+func (r *Resolver) DefaultLocale() string {
+	if r == nil {
+		return Neutral
+	}
+
+	return r.Default
+}
+
+func (r *Resolver) cutPrefix(url string) (string, string) {
+	if r == nil || len(url) < 2 || url[0] != '/' {
+		return Neutral, url
+	}
+
+	end := strings.IndexAny(url[1:], "/?")
+	if end == -1 {
+		end = len(url)
+	} else {
+		end++
+	}
+
+	prefix := strings.ToLower(url[1:end])
+	if !slices.Contains(r.Prefixes, prefix) {
+		return Neutral, url
+	}
+
+	rest := url[end:]
+	if rest == "" || rest[0] == '?' {
+		rest = "/" + rest
+	}
+
+	return prefix, rest
+}
+
+func (m *Match) add(tag string) {
+	if tag == Neutral {
+		return
+	}
+
+	for _, parent := range Parents(tag) {
+		if !slices.Contains(m.Chain, parent) {
+			m.Chain = append(m.Chain, parent)
+		}
+	}
+}
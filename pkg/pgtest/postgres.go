@@ -0,0 +1,63 @@
+package pgtest
+
+import (
+	"context"
+	"github.com/quadgod/seo/pkg/pgm"
+	"github.com/quadgod/seo/pkg/pgm/cli"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"testing"
+	"time"
+)
+
+// Run запускает контейнер postgres, применяет к нему миграции из migrationsDir
+// и возвращает строку подключения. Контейнер останавливается по завершении теста
+func Run(t *testing.T, migrationsDir string) string {
+	t.Helper()
+
+	ctx := context.Background()
+
+	postgresContainer, err := postgres.RunContainer(ctx,
+		testcontainers.WithImage("postgres:alpine3.19"),
+		postgres.WithDatabase("test_db"),
+		postgres.WithUsername("user"),
+		postgres.WithPassword("password"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(5*time.Second)),
+	)
+
+	t.Cleanup(func() {
+		if postgresContainer == nil {
+			return
+		}
+		if err := postgresContainer.Terminate(ctx); err != nil {
+			t.Errorf("failed to terminate container: %s", err)
+		}
+	})
+
+	if err != nil {
+		t.Fatalf("failed to start container: %s", err)
+	}
+
+	connStr, err := postgresContainer.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		t.Fatalf("unable to build connection string")
+	}
+
+	opts := new(pgm.MigratorOptions)
+	opts.ConnectionString = connStr
+	opts.Command = pgm.CommandMigrate
+	opts.Priority = pgm.PriorityFS
+	opts.MigrationsTableSchema = "public"
+	opts.MigrationsTable = "migrations"
+	opts.MigrationsDir = migrationsDir
+
+	if _, err = cli.Migrate(ctx, opts); err != nil {
+		t.Fatalf("failed to apply migrations: %s", err)
+	}
+
+	return connStr
+}
@@ -0,0 +1,209 @@
+package podstate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log/slog"
+	"time"
+)
+
+// DefaultInterval период обновления last_activity и опроса next_generation
+const DefaultInterval = 30 * time.Second
+
+// DefaultMaxRetryBackoff максимальная пауза перед повторной загрузкой генерации, загрузка которой упала
+const DefaultMaxRetryBackoff = 10 * time.Minute
+
+// LoadFunc загружает генерацию в память. Controller переводит под в online только
+// после успешного завершения LoadFunc
+type LoadFunc func(ctx context.Context, generation time.Time) error
+
+type Options struct {
+	// Hostname имя пода в pods_states. Если не задано, используется Hostname()
+	Hostname string
+	// HeartbeatInterval период обновления last_activity
+	HeartbeatInterval time.Duration
+	// PollInterval период опроса next_generation
+	PollInterval time.Duration
+	// MaxRetryBackoff максимальная пауза перед повторной загрузкой упавшей генерации.
+	// Пауза начинается с PollInterval и удваивается после каждой неудачной попытки
+	MaxRetryBackoff time.Duration
+	Logger          *slog.Logger
+}
+
+// Controller реализует протокол pods_states, описанный в начальной миграции:
+// регистрация пода со статусом loading, обновление last_activity, опрос next_generation,
+// загрузка генерации и перевод пода в online с обновлением current_generation
+type Controller struct {
+	pool    *pgxpool.Pool
+	load    LoadFunc
+	opts    Options
+	current *time.Time
+
+	// failed генерация, загрузка которой упала, failures - число неудачных попыток подряд,
+	// retryAt - время, до которого повторная загрузка failed не выполняется
+	failed   *time.Time
+	failures int
+	retryAt  time.Time
+}
+
+// NewController создает контроллер. Незаданные опции заполняются значениями по умолчанию
+func NewController(pool *pgxpool.Pool, load LoadFunc, opts Options) (*Controller, error) {
+	if opts.Hostname == "" {
+		hostname, err := Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("resolve hostname errors: %w", err)
+		}
+		opts.Hostname = hostname
+	}
+
+	if opts.HeartbeatInterval <= 0 {
+		opts.HeartbeatInterval = DefaultInterval
+	}
+
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultInterval
+	}
+
+	if opts.MaxRetryBackoff <= 0 {
+		opts.MaxRetryBackoff = DefaultMaxRetryBackoff
+	}
+
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	return &Controller{pool: pool, load: load, opts: opts}, nil
+}
+
+// Hostname возвращает имя, под которым под зарегистрирован в pods_states
+func (c *Controller) Hostname() string {
+	return c.opts.Hostname
+}
+
+// Run регистрирует под и выполняет протокол до отмены контекста.
+// Ошибки обращения к базе логируются и повторяются на следующей итерации, загрузка упавшей генерации
+// повторяется с паузой (см. Options.MaxRetryBackoff),
+// возвращается только ошибка регистрации
+func (c *Controller) Run(ctx context.Context) error {
+	if err := register(ctx, c.pool, c.opts.Hostname); err != nil {
+		return fmt.Errorf("register pod errors: %w", err)
+	}
+
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		c.heartbeatLoop(ctx)
+	}()
+	defer func() { <-heartbeatDone }()
+
+	ticker := time.NewTicker(c.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.poll(ctx); err != nil && ctx.Err() == nil {
+			c.opts.Logger.Error("pod state poll errors", "hostname", c.opts.Hostname, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Controller) heartbeatLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.opts.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := heartbeat(ctx, c.pool, c.opts.Hostname); err != nil && ctx.Err() == nil {
+				c.opts.Logger.Error("pod heartbeat errors", "hostname", c.opts.Hostname, "error", err)
+			}
+		}
+	}
+}
+
+// poll сравнивает next_generation с загруженной генерацией и загружает более свежую.
+// next_generation не новее загруженной генерации обнуляется, генерация, загрузка которой упала,
+// повторяется не раньше, чем через паузу (см. Options.MaxRetryBackoff), чтобы под не переключался
+// между loading и online на каждой итерации
+func (c *Controller) poll(ctx context.Context) error {
+	next, err := nextGeneration(ctx, c.pool, c.opts.Hostname)
+	if err != nil {
+		return fmt.Errorf("read next generation errors: %w", err)
+	}
+
+	if next == nil {
+		return nil
+	}
+
+	if c.current != nil && !next.After(*c.current) {
+		if err = clearNextGeneration(ctx, c.pool, c.opts.Hostname, *next); err != nil {
+			return fmt.Errorf("clear stale next generation errors: %w", err)
+		}
+		return nil
+	}
+
+	if c.failed != nil && c.failed.Equal(*next) && time.Now().Before(c.retryAt) {
+		return nil
+	}
+
+	if err = markLoading(ctx, c.pool, c.opts.Hostname); err != nil {
+		return fmt.Errorf("mark pod as loading errors: %w", err)
+	}
+
+	c.opts.Logger.Info("loading generation", "hostname", c.opts.Hostname, "generation", *next)
+
+	if loadErr := c.load(ctx, *next); loadErr != nil {
+		c.fail(*next)
+		loadErr = fmt.Errorf(
+			"load generation %s errors (retry at %s): %w",
+			next.Format(time.RFC3339Nano),
+			c.retryAt.Format(time.RFC3339),
+			loadErr,
+		)
+		if c.current != nil {
+			// Старая генерация продолжает обслуживать запросы
+			loadErr = errors.Join(loadErr, markOnline(ctx, c.pool, c.opts.Hostname, *c.current))
+		}
+		return loadErr
+	}
+
+	if err = markOnline(ctx, c.pool, c.opts.Hostname, *next); err != nil {
+		return fmt.Errorf("mark pod as online errors: %w", err)
+	}
+
+	c.current = next
+	c.failed, c.failures = nil, 0
+	c.opts.Logger.Info("generation loaded", "hostname", c.opts.Hostname, "generation", *next)
+
+	return nil
+}
+
+// fail запоминает генерацию, загрузка которой упала, и откладывает ее повторную загрузку
+func (c *Controller) fail(generation time.Time) {
+	if c.failed == nil || !c.failed.Equal(generation) {
+		c.failed, c.failures = &generation, 0
+	}
+
+	c.failures++
+	c.retryAt = time.Now().Add(retryBackoff(c.opts.PollInterval, c.opts.MaxRetryBackoff, c.failures))
+}
+
+// retryBackoff пауза перед повторной загрузкой после failures неудачных попыток подряд:
+// interval, удвоенный за каждую попытку после первой, но не больше maxBackoff
+func retryBackoff(interval, maxBackoff time.Duration, failures int) time.Duration {
+	backoff := interval
+	for i := 1; i < failures && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxBackoff)
+}
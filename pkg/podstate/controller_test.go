@@ -0,0 +1,218 @@
+package podstate
+
+import (
+	"context"
+	"errors"
+	"github.com/quadgod/seo/pkg/pgm/db"
+	"github.com/quadgod/seo/pkg/pgtest"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+type podRow struct {
+	CurrentGeneration *time.Time
+	NextGeneration    *time.Time
+	Status            string
+	LastActivity      time.Time
+}
+
+func Test_Hostname(t *testing.T) {
+	t.Run("should take hostname from environment", func(t *testing.T) {
+		t.Setenv("HOSTNAME", "seo-pod-1")
+
+		hostname, err := Hostname()
+		require.Nil(t, err)
+		require.Equal(t, "seo-pod-1", hostname)
+	})
+
+	t.Run("should fallback to os hostname", func(t *testing.T) {
+		t.Setenv("HOSTNAME", "")
+
+		hostname, err := Hostname()
+		require.Nil(t, err)
+		require.NotEmpty(t, hostname)
+	})
+}
+
+func Test_Controller(t *testing.T) {
+	ctx := context.Background()
+	connStr := pgtest.Run(t, "../../migrations")
+
+	pool, err := db.Connect(ctx, connStr)
+	require.Nil(t, err)
+	defer pool.Close()
+
+	readPod := func(hostname string) podRow {
+		var row podRow
+		err := pool.QueryRow(ctx, `
+			SELECT current_generation, next_generation, status::text, last_activity
+			FROM pods_states WHERE hostname = $1;
+		`, hostname).Scan(&row.CurrentGeneration, &row.NextGeneration, &row.Status, &row.LastActivity)
+		require.Nil(t, err)
+		return row
+	}
+
+	first := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	_, err = pool.Exec(ctx, `INSERT INTO seo_declarations (generation, url) VALUES ($1, '/');`, first)
+	require.Nil(t, err)
+
+	t.Run("should register pod, load latest generation and switch to the next one", func(t *testing.T) {
+		var mu sync.Mutex
+		loaded := make([]time.Time, 0)
+		release := make(chan struct{})
+
+		load := func(ctx context.Context, generation time.Time) error {
+			mu.Lock()
+			loaded = append(loaded, generation.UTC())
+			n := len(loaded)
+			mu.Unlock()
+
+			if n == 2 {
+				// вторая генерация грузится до тех пор, пока тест не проверит статус loading
+				<-release
+			}
+			return nil
+		}
+
+		controller, err := NewController(pool, load, Options{
+			Hostname:          "pod-1",
+			HeartbeatInterval: 20 * time.Millisecond,
+			PollInterval:      50 * time.Millisecond,
+		})
+		require.Nil(t, err)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- controller.Run(runCtx) }()
+
+		require.Eventually(t, func() bool {
+			row := readPod("pod-1")
+			return row.Status == string(StatusOnline) && row.CurrentGeneration != nil
+		}, 5*time.Second, 20*time.Millisecond)
+
+		row := readPod("pod-1")
+		require.True(t, first.Equal(*row.CurrentGeneration))
+		require.Nil(t, row.NextGeneration)
+
+		_, err = pool.Exec(ctx, `UPDATE pods_states SET next_generation = $1 WHERE hostname = 'pod-1';`, second)
+		require.Nil(t, err)
+
+		require.Eventually(t, func() bool {
+			return readPod("pod-1").Status == string(StatusLoading)
+		}, 5*time.Second, 20*time.Millisecond)
+
+		activity := readPod("pod-1").LastActivity
+		require.Eventually(t, func() bool {
+			return readPod("pod-1").LastActivity.After(activity)
+		}, 5*time.Second, 20*time.Millisecond, "heartbeat must continue while loading")
+
+		close(release)
+
+		require.Eventually(t, func() bool {
+			row := readPod("pod-1")
+			return row.Status == string(StatusOnline) && second.Equal(*row.CurrentGeneration)
+		}, 5*time.Second, 20*time.Millisecond)
+
+		cancel()
+		require.Nil(t, <-done)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Equal(t, []time.Time{first, second}, loaded)
+	})
+
+	t.Run("should keep current generation online when next one failed to load", func(t *testing.T) {
+		var mu sync.Mutex
+		attempts := 0
+
+		load := func(ctx context.Context, generation time.Time) error {
+			if generation.Equal(second) {
+				mu.Lock()
+				attempts++
+				mu.Unlock()
+				return errors.New("broken generation")
+			}
+			return nil
+		}
+
+		controller, err := NewController(pool, load, Options{
+			Hostname:          "pod-2",
+			HeartbeatInterval: 20 * time.Millisecond,
+			PollInterval:      50 * time.Millisecond,
+			MaxRetryBackoff:   time.Minute,
+		})
+		require.Nil(t, err)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- controller.Run(runCtx) }()
+
+		require.Eventually(t, func() bool {
+			row := readPod("pod-2")
+			return row.Status == string(StatusOnline) && row.CurrentGeneration != nil
+		}, 5*time.Second, 20*time.Millisecond)
+
+		_, err = pool.Exec(ctx, `UPDATE pods_states SET next_generation = $1 WHERE hostname = 'pod-2';`, second)
+		require.Nil(t, err)
+
+		time.Sleep(300 * time.Millisecond)
+
+		row := readPod("pod-2")
+		require.Equal(t, string(StatusOnline), row.Status)
+		require.True(t, first.Equal(*row.CurrentGeneration))
+		require.True(t, second.Equal(*row.NextGeneration))
+
+		cancel()
+		require.Nil(t, <-done)
+
+		mu.Lock()
+		defer mu.Unlock()
+		// 50ms, 100ms и 200ms паузы между попытками за 300ms дают не больше трех попыток вместо шести
+		require.LessOrEqual(t, attempts, 3)
+	})
+
+	t.Run("should clear stale next generation", func(t *testing.T) {
+		controller, err := NewController(pool, func(ctx context.Context, generation time.Time) error { return nil }, Options{
+			Hostname:          "pod-3",
+			HeartbeatInterval: 20 * time.Millisecond,
+			PollInterval:      50 * time.Millisecond,
+		})
+		require.Nil(t, err)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error)
+		go func() { done <- controller.Run(runCtx) }()
+
+		require.Eventually(t, func() bool {
+			row := readPod("pod-3")
+			return row.Status == string(StatusOnline) && row.CurrentGeneration != nil
+		}, 5*time.Second, 20*time.Millisecond)
+
+		current := *readPod("pod-3").CurrentGeneration
+		_, err = pool.Exec(ctx, `UPDATE pods_states SET next_generation = $1 WHERE hostname = 'pod-3';`, current.Add(-time.Hour))
+		require.Nil(t, err)
+
+		require.Eventually(t, func() bool {
+			return readPod("pod-3").NextGeneration == nil
+		}, 5*time.Second, 20*time.Millisecond)
+
+		row := readPod("pod-3")
+		require.Equal(t, string(StatusOnline), row.Status)
+		require.True(t, current.Equal(*row.CurrentGeneration))
+
+		cancel()
+		require.Nil(t, <-done)
+	})
+}
+
+func Test_retryBackoff(t *testing.T) {
+	require.Equal(t, time.Second, retryBackoff(time.Second, time.Minute, 1))
+	require.Equal(t, 2*time.Second, retryBackoff(time.Second, time.Minute, 2))
+	require.Equal(t, 8*time.Second, retryBackoff(time.Second, time.Minute, 4))
+	require.Equal(t, time.Minute, retryBackoff(time.Second, time.Minute, 10))
+	require.Equal(t, time.Minute, retryBackoff(time.Second, time.Minute, 1000))
+}
@@ -0,0 +1,15 @@
+package podstate
+
+import (
+	"os"
+)
+
+// Hostname возвращает имя пода из переменной окружения HOSTNAME,
+// а если она не задана - имя хоста из операционной системы
+func Hostname() (string, error) {
+	if hostname := os.Getenv("HOSTNAME"); hostname != "" {
+		return hostname, nil
+	}
+
+	return os.Hostname()
+}
@@ -0,0 +1,91 @@
+package podstate
+
+import (
+	"context"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
+)
+
+// register регистрирует под в pods_states со статусом loading и выставляет
+// next_generation в номер последней генерации из seo_declarations.
+// Если под с таким hostname уже есть (например, после рестарта), то его запись переиспользуется
+func register(ctx context.Context, pool *pgxpool.Pool, hostname string) error {
+	tag, err := pool.Exec(ctx, `
+		UPDATE "public"."pods_states"
+		SET current_generation = null,
+			next_generation = (SELECT max(generation) FROM "public"."seo_declarations"),
+			status = 'loading',
+			last_activity = CURRENT_TIMESTAMP
+		WHERE hostname = $1;
+	`, hostname)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	_, err = pool.Exec(ctx, `
+		INSERT INTO "public"."pods_states" (current_generation, next_generation, status, hostname, last_activity)
+		VALUES (null, (SELECT max(generation) FROM "public"."seo_declarations"), 'loading', $1, CURRENT_TIMESTAMP);
+	`, hostname)
+
+	return err
+}
+
+// heartbeat обновляет last_activity пода
+func heartbeat(ctx context.Context, pool *pgxpool.Pool, hostname string) error {
+	_, err := pool.Exec(ctx, `
+		UPDATE "public"."pods_states" SET last_activity = CURRENT_TIMESTAMP WHERE hostname = $1;
+	`, hostname)
+
+	return err
+}
+
+// nextGeneration возвращает next_generation пода или nil, если загружать нечего
+func nextGeneration(ctx context.Context, pool *pgxpool.Pool, hostname string) (*time.Time, error) {
+	var next *time.Time
+	err := pool.QueryRow(ctx, `
+		SELECT next_generation FROM "public"."pods_states" WHERE hostname = $1 LIMIT 1;
+	`, hostname).Scan(&next)
+
+	return next, err
+}
+
+// markLoading выставляет поду статус loading
+func markLoading(ctx context.Context, pool *pgxpool.Pool, hostname string) error {
+	_, err := pool.Exec(ctx, `
+		UPDATE "public"."pods_states"
+		SET status = 'loading', last_activity = CURRENT_TIMESTAMP
+		WHERE hostname = $1;
+	`, hostname)
+
+	return err
+}
+
+// markOnline фиксирует загруженную генерацию и выставляет поду статус online.
+// next_generation обнуляется только если за время загрузки его никто не поменял
+func markOnline(ctx context.Context, pool *pgxpool.Pool, hostname string, generation time.Time) error {
+	_, err := pool.Exec(ctx, `
+		UPDATE "public"."pods_states"
+		SET current_generation = $2,
+			next_generation = CASE WHEN next_generation = $2 THEN null ELSE next_generation END,
+			status = 'online',
+			last_activity = CURRENT_TIMESTAMP
+		WHERE hostname = $1;
+	`, hostname, generation)
+
+	return err
+}
+
+// clearNextGeneration обнуляет next_generation, если он все еще равен generation
+func clearNextGeneration(ctx context.Context, pool *pgxpool.Pool, hostname string, generation time.Time) error {
+	_, err := pool.Exec(ctx, `
+		UPDATE "public"."pods_states"
+		SET next_generation = null
+		WHERE hostname = $1 AND next_generation = $2;
+	`, hostname, generation)
+
+	return err
+}
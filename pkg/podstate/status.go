@@ -0,0 +1,9 @@
+package podstate
+
+// Status значение перечисления API_STATUS из таблицы pods_states
+type Status string
+
+const (
+	StatusLoading Status = "loading"
+	StatusOnline  Status = "online"
+)
@@ -0,0 +1,212 @@
+package radixtrie
+
+// backtrack is the state of the backtracking `Search` of a url, see `Trie.SetBacktracking`.
+type backtrack struct {
+	q        string // the path of the url, lowercased if the trie folds the case.
+	vq       string // the path of the url the values are taken from, see `foldPath`.
+	rawQuery string
+	ignore   QueryIgnoreFunc
+	params   ParamsSetter
+	tr       *tracer
+	// values are the named parameters and wildcard values of the current branch.
+	values []string
+}
+
+func (t *Trie) searchBacktracking(q, vq, rawQuery string, params ParamsSetter, tr *tracer) *Node {
+	var valuesBuf [8]string
+	b := &backtrack{
+		q:        q,
+		vq:       vq,
+		rawQuery: rawQuery,
+		ignore:   t.queryIgnore(),
+		params:   params,
+		tr:       tr,
+		values:   valuesBuf[:0],
+	}
+
+	return b.match(t.root, 1)
+}
+
+// match returns the pattern node which matches the url from the "pos"
+// (the start of a segment) below the "n", trying static, named and wildcard children in order.
+func (b *backtrack) match(n *Node, pos int) *Node {
+	end := len(b.q)
+	if pos >= end {
+		return b.accept(n)
+	}
+
+	i := pos
+	for i < end && b.q[i] != pathSepRune {
+		i++
+	}
+	segment, value := b.q[pos:i], b.vq[pos:i]
+
+	if child := n.getChild(segment); child != nil && isStaticLabel(child.label) {
+		// the rest of a compressed edge, e.g. "/b/c" of "a/b/c",
+		// must be followed by the end of the url or a slash.
+		rest := child.label[len(segment):]
+		matched := rest == "" || hasSegmentsPrefix(b.q[i:], rest)
+		if b.tr != nil {
+			b.tr.step(StepStatic, b.vq[pos:min(i+len(rest), end)], child, matched)
+		}
+
+		if matched {
+			if found := b.match(child, nextSegment(b.q, i+len(rest))); found != nil {
+				return found
+			}
+
+			if b.tr != nil {
+				b.tr.step(StepBacktrack, value, n, false)
+			}
+		}
+	}
+
+	if value != "" {
+		if named := n.namedChild(value); named != nil {
+			if b.tr != nil {
+				b.tr.step(StepNamed, value, named, true)
+			}
+
+			b.values = append(b.values, value)
+			if found := b.match(named, nextSegment(b.q, i)); found != nil {
+				return found
+			}
+			b.values = b.values[:len(b.values)-1]
+
+			if b.tr != nil {
+				b.tr.step(StepBacktrack, value, n, false)
+			}
+		} else if b.tr != nil {
+			b.tr.traceNamed(n, value)
+		}
+	}
+
+	if n.childWildcardParameter {
+		wildcard := n.getChild(WildcardParamStart)
+		if b.tr != nil {
+			b.tr.step(StepWildcard, b.vq[pos:], wildcard, true)
+		}
+
+		b.values = append(b.values, b.vq[pos:])
+		if found := b.accept(wildcard); found != nil {
+			return found
+		}
+		b.values = b.values[:len(b.values)-1]
+	}
+
+	if b.tr != nil {
+		b.tr.step(StepMiss, value, n, false)
+	}
+
+	return nil
+}
+
+// accept returns the node or its matching query variant if it is a pattern and sets its params.
+func (b *backtrack) accept(n *Node) *Node {
+	if n.queries != nil {
+		if v := n.matchQuery(b.rawQuery, b.ignore, b.values, b.params, b.tr); v != nil {
+			return v
+		}
+	}
+
+	if b.tr != nil {
+		b.tr.step(StepEnd, "", n, n.end)
+	}
+
+	if !n.end {
+		return nil
+	}
+
+	for i, value := range b.values {
+		if len(n.paramKeys) > i {
+			b.params.Set(n.paramKeys[i], value)
+		}
+	}
+
+	return n
+}
+
+// frozenBacktrack is the `backtrack` of the `Frozen` trie.
+type frozenBacktrack struct {
+	f        *Frozen
+	q        string
+	vq       string
+	rawQuery string
+	params   ParamsSetter
+	values   []string
+}
+
+func (f *Frozen) searchBacktracking(q, vq, rawQuery string, params ParamsSetter) *FrozenNode {
+	var valuesBuf [8]string
+	b := &frozenBacktrack{
+		f:        f,
+		q:        q,
+		vq:       vq,
+		rawQuery: rawQuery,
+		params:   params,
+		values:   valuesBuf[:0],
+	}
+
+	return b.match(0, 1)
+}
+
+// match is the `backtrack.match` of the frozen node "n".
+func (b *frozenBacktrack) match(n int32, pos int) *FrozenNode {
+	end := len(b.q)
+	if pos >= end {
+		return b.accept(n)
+	}
+
+	i := pos
+	for i < end && b.q[i] != pathSepRune {
+		i++
+	}
+	segment, value := b.q[pos:i], b.vq[pos:i]
+
+	if child, rest := b.f.child(n, segment); child != -1 && (rest == "" || hasSegmentsPrefix(b.q[i:], rest)) {
+		if found := b.match(child, nextSegment(b.q, i+len(rest))); found != nil {
+			return found
+		}
+	}
+
+	if value != "" {
+		if named := b.f.namedChild(n, value); named != -1 {
+			b.values = append(b.values, value)
+			if found := b.match(named, nextSegment(b.q, i)); found != nil {
+				return found
+			}
+			b.values = b.values[:len(b.values)-1]
+		}
+	}
+
+	if wildcard := b.f.nodes[n].wildcard; wildcard != -1 {
+		b.values = append(b.values, b.vq[pos:])
+		if found := b.accept(wildcard); found != nil {
+			return found
+		}
+		b.values = b.values[:len(b.values)-1]
+	}
+
+	return nil
+}
+
+func (b *frozenBacktrack) accept(n int32) *FrozenNode {
+	fn := &b.f.nodes[n]
+	if fn.queryCount != 0 {
+		if v := b.f.matchQuery(n, b.rawQuery, b.values, b.params); v != nil {
+			return v
+		}
+	}
+
+	if !fn.end {
+		return nil
+	}
+
+	for i, value := range b.values {
+		if int(fn.paramCount) > i {
+			b.params.Set(b.f.paramKey(n, i), value)
+		}
+	}
+
+	return fn
+}
@@ -0,0 +1,238 @@
+package radixtrie
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func Test_SearchBacktracking(t *testing.T) {
+	trie := NewTrie()
+	trie.SetBacktracking(true)
+	for _, p := range []string{
+		"/",
+		"/a/static/x",
+		"/a/:p/y",
+		"/a/:p/:q/z",
+		"/a/static/deep/x",
+		"/a/*rest",
+		"/b/:id<int>/x",
+		"/b/x/y",
+		"/c/:p?color",
+		"/c/static/x",
+	} {
+		require.Nil(t, trie.Insert(p))
+	}
+
+	tests := []struct {
+		url     string
+		pattern string
+		params  map[string]string
+	}{
+		{"/", "/", map[string]string{}},
+		{"/a/static/x", "/a/static/x", map[string]string{}},
+		{"/a/static/y", "/a/:p/y", map[string]string{"p": "static"}},
+		{"/a/static/deep/z", "/a/:p/:q/z", map[string]string{"p": "static", "q": "deep"}},
+		{"/a/static/deep/y", "/a/*rest", map[string]string{"rest": "static/deep/y"}},
+		{"/a/static", "/a/*rest", map[string]string{"rest": "static"}},
+		{"/a/static/y/", "/a/:p/y", map[string]string{"p": "static"}},
+		{"/b/42/x", "/b/:id<int>/x", map[string]string{"id": "42"}},
+		{"/b/x/y", "/b/x/y", map[string]string{}},
+		{"/b/x/x", "", nil},
+		{"/c/static?color=red", "/c/:p?color", map[string]string{"p": "static", "color": "red"}},
+		{"/c/static", "", nil},
+		{"/d", "", nil},
+		{"/a/:/y", "/a/:p/y", map[string]string{"p": ":"}},
+		{"/a/*", "/a/*rest", map[string]string{"rest": "*"}},
+		{"/b/:/x", "", nil},
+	}
+
+	frozen := trie.Freeze()
+	for _, tt := range tests {
+		p := new(Params)
+		n := trie.Search(tt.url, p)
+		requireFrozenSearch(t, frozen, tt.url, tt.pattern, tt.params)
+		if tt.pattern == "" {
+			require.Nil(t, n, tt.url)
+			continue
+		}
+
+		require.NotNil(t, n, tt.url)
+		require.Equal(t, tt.pattern, n.String(), tt.url)
+		require.Equal(t, tt.params, p.Map(), tt.url)
+	}
+
+	t.Run("should not backtrack by default", func(t *testing.T) {
+		trie.SetBacktracking(false)
+		defer trie.SetBacktracking(true)
+
+		require.Equal(t, "/a/*rest", trie.Search("/a/static/deep/z", new(Params)).String())
+		require.Nil(t, trie.Search("/c/static?color=red", new(Params)))
+		require.Equal(t, "/a/*rest", trie.Freeze().Search("/a/static/deep/z", new(Params)).String())
+	})
+}
+
+// requireFrozenSearch requires the frozen trie to find the "pattern" with the "params" for the "url".
+func requireFrozenSearch(t *testing.T, frozen *Frozen, url, pattern string, params map[string]string) {
+	t.Helper()
+
+	p := new(Params)
+	n := frozen.Search(url, p)
+	if pattern == "" {
+		require.Nil(t, n, "frozen %s", url)
+		return
+	}
+
+	require.NotNil(t, n, "frozen %s", url)
+	require.Equal(t, pattern, n.String(), "frozen %s", url)
+	require.Equal(t, params, p.Map(), "frozen %s", url)
+}
+
+// bruteMatch matches the url against every pattern and returns the pattern
+// which has a static segment, then a named parameter, at the first position where the matches differ.
+func bruteMatch(patterns []string, url string) (string, map[string]string) {
+	segments := strings.Split(url[1:], pathSep)
+
+	var (
+		best       string
+		bestKinds  []int
+		bestParams map[string]string
+	)
+
+	for _, pattern := range patterns {
+		kinds, params, ok := bruteMatchPattern(pattern, segments)
+		if !ok {
+			continue
+		}
+
+		if bestKinds == nil || lessKinds(kinds, bestKinds) {
+			best, bestKinds, bestParams = pattern, kinds, params
+		}
+	}
+
+	return best, bestParams
+}
+
+func bruteMatchPattern(pattern string, segments []string) (kinds []int, params map[string]string, ok bool) {
+	params = make(map[string]string)
+	parts := slowPathSplit(pattern)
+
+	for i, part := range parts {
+		if i >= len(segments) {
+			return nil, nil, false
+		}
+
+		switch part[0] {
+		case WildcardParamStart[0]:
+			params[part[1:]] = strings.Join(segments[i:], pathSep)
+			return append(kinds, 2), params, true
+		case ParamStart[0]:
+			name, spec, _ := splitConstraint(part[1:])
+			if segments[i] == "" {
+				return nil, nil, false
+			}
+
+			if spec != "" {
+				c, err := parseConstraint(spec)
+				if err != nil || !c.match(segments[i]) {
+					return nil, nil, false
+				}
+			}
+
+			params[name] = segments[i]
+			kinds = append(kinds, 1)
+		default:
+			if part != segments[i] {
+				return nil, nil, false
+			}
+			kinds = append(kinds, 0)
+		}
+	}
+
+	return kinds, params, len(parts) == len(segments)
+}
+
+func lessKinds(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+func randomBacktrackPattern(r *rand.Rand) string {
+	var b strings.Builder
+	n := 1 + r.Intn(4)
+	for i := 0; i < n; i++ {
+		b.WriteString(pathSep)
+		switch k := r.Intn(10); {
+		case k < 5:
+			b.WriteString([]string{"a", "b", "1"}[r.Intn(3)])
+		case k < 7:
+			b.WriteString(ParamStart + []string{"p", "q"}[r.Intn(2)] + fmt.Sprint(i))
+		case k < 8:
+			b.WriteString(ParamStart + "n" + fmt.Sprint(i) + "<int>")
+		default:
+			b.WriteString(WildcardParamStart + "w")
+			return b.String()
+		}
+	}
+
+	return b.String()
+}
+
+func randomBacktrackURL(r *rand.Rand) string {
+	var b strings.Builder
+	n := 1 + r.Intn(5)
+	for i := 0; i < n; i++ {
+		b.WriteString(pathSep)
+		if i < n-1 && r.Intn(10) == 0 {
+			continue // an empty segment.
+		}
+		b.WriteString([]string{"a", "b", "c", "1", "-2", ParamStart, WildcardParamStart}[r.Intn(7)])
+	}
+
+	return b.String()
+}
+
+func FuzzSearchBacktracking(f *testing.F) {
+	for seed := int64(0); seed < 100; seed++ {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+
+		trie := NewTrie()
+		trie.SetBacktracking(true)
+		var patterns []string
+		for i := 0; i < 2+r.Intn(30); i++ {
+			pattern := randomBacktrackPattern(r)
+			if trie.Insert(pattern) == nil {
+				patterns = append(patterns, pattern)
+			}
+		}
+
+		frozen := trie.Freeze()
+		for i := 0; i < 200; i++ {
+			url := randomBacktrackURL(r)
+			expected, expectedParams := bruteMatch(patterns, url)
+			requireFrozenSearch(t, frozen, url, expected, expectedParams)
+
+			p := new(Params)
+			n := trie.Search(url, p)
+			if expected == "" {
+				require.Nil(t, n, "%s in %v", url, patterns)
+				continue
+			}
+
+			require.NotNil(t, n, "%s in %v", url, patterns)
+			require.Equal(t, expected, n.String(), "%s in %v", url, patterns)
+			require.Equal(t, expectedParams, p.Map(), "%s in %v", url, patterns)
+		}
+	})
+}
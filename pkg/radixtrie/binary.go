@@ -0,0 +1,516 @@
+package radixtrie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// The binary snapshot layout is:
+//
+//	magic (4 bytes) | version (uint16, big endian) | root node | crc32 of all previous bytes (uint32, big endian)
+//
+// node: flags byte | key, if end | data, if has data | localized count and (locale, data)..., if has localized data |
+// redirect target and status, if has redirect | queries count, if has queries | query node... |
+// children count | (edge label, node)...
+// The query nodes are the query variants of the path, they are the final nodes without children.
+// The edge label of a constrained named parameter is :<spec>, e.g. :<int>.
+// Strings are uvarint length prefixed, the numbers are uvarints/varints.
+// Node's paramKeys, staticKey and the dynamic child flags are derived from the key and the children on decode.
+const (
+	binaryMagic   = "RXTR"
+	binaryVersion = 6
+)
+
+const (
+	nodeFlagEnd byte = 1 << iota
+	nodeFlagData
+	nodeFlagQueries
+	nodeFlagRedirect
+	nodeFlagLocalized
+)
+
+const (
+	dataFlagCompiled byte = 1 << iota
+)
+
+// ErrInvalidBinary is returned by `UnmarshalBinary` and `ReadFrom` when the snapshot is corrupted
+// or was written by an unsupported version.
+var ErrInvalidBinary = errors.New("invalid trie binary")
+
+// MarshalBinary encodes the trie into a versioned binary snapshot with a checksum,
+// see `UnmarshalBinary`.
+func (t *Trie) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if _, err := t.WriteTo(buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteTo streams the binary snapshot of the trie to "w", see `MarshalBinary`.
+func (t *Trie) WriteTo(w io.Writer) (int64, error) {
+	counter := &countingWriter{w: w}
+	hash := crc32.NewIEEE()
+	bw := bufio.NewWriter(io.MultiWriter(counter, hash))
+	e := &encoder{w: bw}
+
+	e.raw([]byte(binaryMagic))
+	e.raw(binary.BigEndian.AppendUint16(nil, binaryVersion))
+	e.node(t.root)
+
+	if e.err == nil {
+		e.err = bw.Flush()
+	}
+
+	if e.err == nil {
+		_, e.err = counter.Write(binary.BigEndian.AppendUint32(nil, hash.Sum32()))
+	}
+
+	return counter.n, e.err
+}
+
+// UnmarshalBinary replaces the trie's content with the snapshot made by `MarshalBinary`.
+// The trie is left untouched if the snapshot is invalid.
+func (t *Trie) UnmarshalBinary(data []byte) error {
+	headerLen := len(binaryMagic) + 2
+	if len(data) < headerLen+4 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return fmt.Errorf("%w: bad header", ErrInvalidBinary)
+	}
+
+	if version := binary.BigEndian.Uint16(data[len(binaryMagic):]); version != binaryVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidBinary, version)
+	}
+
+	payload, checksum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(payload) != checksum {
+		return fmt.Errorf("%w: checksum mismatch", ErrInvalidBinary)
+	}
+
+	d := &decoder{data: payload, off: headerLen}
+	root := d.node()
+	if d.err == nil && d.off != len(payload) {
+		d.fail("unexpected trailing bytes")
+	}
+
+	if d.err != nil {
+		return d.err
+	}
+
+	t.root = root
+	t.dropKeys()
+	t.hasRootWildcard = root.hasChild(WildcardParamStart)
+	t.hasRootSlash = root.hasChild(pathSep)
+
+	return nil
+}
+
+// ReadFrom reads the whole "r" and decodes it by `UnmarshalBinary`.
+func (t *Trie) ReadFrom(r io.Reader) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(data)), err
+	}
+
+	return int64(len(data)), t.UnmarshalBinary(data)
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type encoder struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+	err error
+}
+
+func (e *encoder) raw(p []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(p)
+	}
+}
+
+func (e *encoder) byte(b byte) {
+	if e.err == nil {
+		e.err = e.w.WriteByte(b)
+	}
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.raw(e.buf[:binary.PutUvarint(e.buf[:], v)])
+}
+
+func (e *encoder) varint(v int64) {
+	e.raw(e.buf[:binary.PutVarint(e.buf[:], v)])
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
+
+func (e *encoder) optString(s *string) {
+	if s == nil {
+		e.byte(0)
+		return
+	}
+
+	e.byte(1)
+	e.string(*s)
+}
+
+func (e *encoder) node(n *Node) {
+	var flags byte
+	if n.end {
+		flags |= nodeFlagEnd
+	}
+	if n.Data != nil {
+		flags |= nodeFlagData
+	}
+	if n.queries != nil {
+		flags |= nodeFlagQueries
+	}
+	if n.Redirect != nil {
+		flags |= nodeFlagRedirect
+	}
+	if n.Localized != nil {
+		flags |= nodeFlagLocalized
+	}
+	e.byte(flags)
+
+	if n.end {
+		e.string(n.key)
+	}
+
+	if n.Data != nil {
+		e.data(n.Data)
+	}
+
+	if n.Localized != nil {
+		locales := n.Locales()
+		e.uvarint(uint64(len(locales)))
+		for _, locale := range locales {
+			e.string(locale)
+			e.data(n.Localized[locale])
+		}
+	}
+
+	if n.Redirect != nil {
+		e.string(n.Redirect.Target)
+		e.uvarint(uint64(n.Redirect.Status))
+	}
+
+	if n.queries != nil {
+		e.uvarint(uint64(len(n.queries)))
+		for _, v := range n.queries {
+			e.node(v)
+		}
+	}
+
+	edges := make([]string, 0, len(n.children))
+	for s := range n.children {
+		edges = append(edges, s)
+	}
+	sort.Strings(edges)
+
+	e.uvarint(uint64(len(edges)))
+	for _, s := range edges {
+		child := n.children[s]
+		if child.constraint != nil {
+			e.string(child.label + string(constraintStart) + child.constraint.spec + string(constraintEnd))
+		} else {
+			e.string(child.label)
+		}
+		e.node(child)
+	}
+}
+
+func (e *encoder) data(d *SeoData) {
+	var flags byte
+	if d.templates != nil || d.hreflangTemplates != nil {
+		flags |= dataFlagCompiled
+	}
+	e.byte(flags)
+
+	for _, f := range d.stringFields() {
+		e.optString(*f.value)
+	}
+
+	e.uvarint(uint64(len(d.Faq)))
+	for _, item := range d.Faq {
+		e.string(item.Question)
+		e.string(item.Answer)
+		e.varint(int64(item.Order))
+	}
+
+	e.uvarint(uint64(len(d.TagsCloud)))
+	for _, item := range d.TagsCloud {
+		e.string(item.Label)
+		e.string(item.Href)
+		e.uvarint(math.Float64bits(item.Weight))
+	}
+
+	e.uvarint(uint64(len(d.Hreflang)))
+	for _, item := range d.Hreflang {
+		e.string(item.Lang)
+		e.string(item.Href)
+	}
+}
+
+type decoder struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (d *decoder) fail(reason string) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: %s at offset %d", ErrInvalidBinary, reason, d.off)
+	}
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+
+	if d.off >= len(d.data) {
+		d.fail("unexpected end of data")
+		return 0
+	}
+
+	b := d.data[d.off]
+	d.off++
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(d.data[d.off:])
+	if n <= 0 {
+		d.fail("bad uvarint")
+		return 0
+	}
+
+	d.off += n
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Varint(d.data[d.off:])
+	if n <= 0 {
+		d.fail("bad varint")
+		return 0
+	}
+
+	d.off += n
+	return v
+}
+
+// count reads a length prefix, each of the counted items takes at least one byte.
+func (d *decoder) count() int {
+	v := d.uvarint()
+	if v > uint64(len(d.data)-d.off) {
+		d.fail("length out of range")
+		return 0
+	}
+
+	return int(v)
+}
+
+func (d *decoder) string() string {
+	l := d.count()
+	if d.err != nil {
+		return ""
+	}
+
+	s := string(d.data[d.off : d.off+l])
+	d.off += l
+	return s
+}
+
+func (d *decoder) optString() *string {
+	if d.byte() == 0 || d.err != nil {
+		return nil
+	}
+
+	s := d.string()
+	return &s
+}
+
+func (d *decoder) node() *Node {
+	n := NewNode()
+
+	flags := d.byte()
+	if flags&nodeFlagEnd != 0 {
+		n.end = true
+		n.key = d.string()
+		n.paramKeys = ParamKeys(n.key)
+		path, _, _ := strings.Cut(n.key, QueryStart)
+		n.staticKey = resolveStaticPart(path)
+	}
+
+	if flags&nodeFlagData != 0 {
+		n.Data = d.seoData(n.paramKeys)
+	}
+
+	if flags&nodeFlagLocalized != 0 {
+		count := d.count()
+		n.Localized = make(map[string]*SeoData, count)
+		for i := 0; i < count && d.err == nil; i++ {
+			locale := d.string()
+			if locale == "" {
+				d.fail("empty locale")
+				break
+			}
+			n.Localized[locale] = d.seoData(n.paramKeys)
+		}
+	}
+
+	if flags&nodeFlagRedirect != 0 {
+		n.Redirect = d.redirect(n.paramKeys)
+	}
+
+	if flags&nodeFlagQueries != 0 {
+		queries := d.count()
+		for i := 0; i < queries && d.err == nil; i++ {
+			v := d.node()
+			if d.err != nil {
+				break
+			}
+
+			_, rawQuery, hasQuery := strings.Cut(v.key, QueryStart)
+			query, err := parseQueryPattern(rawQuery)
+			if !v.end || !hasQuery || err != nil || v.children != nil || v.queries != nil || n.findQuery(query.canonical) != nil {
+				d.fail("invalid query node")
+				break
+			}
+
+			v.query = query
+			n.addQuery(v)
+		}
+	}
+
+	children := d.count()
+	for i := 0; i < children && d.err == nil; i++ {
+		label := d.string()
+		child := d.node()
+		if d.err != nil {
+			break
+		}
+
+		if label != ParamStart && strings.HasPrefix(label, ParamStart) {
+			_, spec, ok := splitConstraint(label[1:])
+			c, err := parseConstraint(spec)
+			if !ok || err != nil {
+				d.fail("invalid constraint")
+				break
+			}
+
+			child.constraint = c
+			label = ParamStart
+		}
+
+		if label == "" || n.hasChild(edgeKey(label)) {
+			d.fail("invalid edge")
+			break
+		}
+
+		child.label = label
+		n.addChild(child)
+		switch label {
+		case ParamStart:
+			n.childNamedParameter = true
+			n.hasDynamicChild = true
+		case WildcardParamStart:
+			n.childWildcardParameter = true
+			n.hasDynamicChild = true
+		}
+	}
+
+	return n
+}
+
+func (d *decoder) redirect(paramKeys []string) *Redirect {
+	r := &Redirect{Target: d.string()}
+	status := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+
+	if status > 999 {
+		d.fail("invalid redirect status")
+		return nil
+	}
+	r.Status = int(status)
+
+	if err := r.Compile(paramKeys); err != nil {
+		d.fail(fmt.Sprintf("compile redirect: %v", err))
+		return nil
+	}
+
+	return r
+}
+
+func (d *decoder) seoData(paramKeys []string) *SeoData {
+	data := new(SeoData)
+
+	flags := d.byte()
+	for _, f := range data.stringFields() {
+		*f.value = d.optString()
+	}
+
+	if l := d.count(); l > 0 {
+		data.Faq = make([]FaqItem, l)
+		for i := range data.Faq {
+			data.Faq[i] = FaqItem{Question: d.string(), Answer: d.string(), Order: int(d.varint())}
+		}
+	}
+
+	if l := d.count(); l > 0 {
+		data.TagsCloud = make([]TagsCloudItem, l)
+		for i := range data.TagsCloud {
+			data.TagsCloud[i] = TagsCloudItem{Label: d.string(), Href: d.string(), Weight: math.Float64frombits(d.uvarint())}
+		}
+	}
+
+	if l := d.count(); l > 0 {
+		data.Hreflang = make([]HreflangAlternate, l)
+		for i := range data.Hreflang {
+			data.Hreflang[i] = HreflangAlternate{Lang: d.string(), Href: d.string()}
+		}
+	}
+
+	if d.err == nil && flags&dataFlagCompiled != 0 {
+		if err := data.Compile(paramKeys); err != nil {
+			d.fail(fmt.Sprintf("compile data: %v", err))
+		}
+	}
+
+	return data
+}
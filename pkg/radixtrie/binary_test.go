@@ -0,0 +1,101 @@
+package radixtrie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newBinaryTestTrie(t *testing.T) *Trie {
+	trie := NewTrie()
+
+	category := &SeoData{
+		MetaTitle:     strPtr("{{category}} catalog"),
+		MetaRobots:    strPtr("index, follow"),
+		CanonicalLink: strPtr("/catalog/{{category}}"),
+		Faq:           []FaqItem{{Question: "q1", Answer: "a1", Order: -1}, {Question: "q2", Answer: "a2", Order: 3}},
+		TagsCloud:     []TagsCloudItem{{Label: "Sneakers", Href: "/catalog/sneakers", Weight: 1.25}},
+		Hreflang:      []HreflangAlternate{{Lang: "en", Href: "/en/catalog/{{category}}"}},
+	}
+	require.Nil(t, category.Compile(ParamKeys("/catalog/:category")))
+
+	require.Nil(t, trie.Insert("/", WithData(&SeoData{MetaTitle: strPtr("main")})))
+	require.Nil(t, trie.Insert("/catalog/:category", WithData(category)))
+	require.Nil(t, trie.Insert("/catalog/:category/*rest"))
+	require.Nil(t, trie.Insert("/about/company", WithData(&SeoData{MetaDescription: strPtr("{{not compiled}}")})))
+	require.Nil(t, trie.Insert("/*any", WithData(&SeoData{})))
+
+	return trie
+}
+
+func Test_MarshalBinary(t *testing.T) {
+	queries := []string{"/", "/catalog/shoes", "/catalog/shoes/red/42", "/about/company", "/about", "/unknown/page"}
+
+	t.Run("should restore the same trie", func(t *testing.T) {
+		trie := newBinaryTestTrie(t)
+
+		data, err := trie.MarshalBinary()
+		require.Nil(t, err)
+
+		restored := NewTrie()
+		require.Nil(t, restored.UnmarshalBinary(data))
+		require.Equal(t, trie.hasRootWildcard, restored.hasRootWildcard)
+		require.Equal(t, trie.hasRootSlash, restored.hasRootSlash)
+
+		for _, q := range queries {
+			expectedParams, params := new(Params), new(Params)
+			expected, n := trie.Search(q, expectedParams), restored.Search(q, params)
+
+			require.Equal(t, expected.String(), n.String(), q)
+			require.Equal(t, expectedParams.Map(), params.Map(), q)
+			require.Equal(t, expected.paramKeys, n.paramKeys, q)
+			require.Equal(t, expected.staticKey, n.staticKey, q)
+			require.Equal(t, expected.Data.Render(expectedParams), n.Data.Render(params), q)
+		}
+
+		again, err := restored.MarshalBinary()
+		require.Nil(t, err)
+		require.Equal(t, data, again, "encoding must be deterministic")
+	})
+
+	t.Run("should stream through WriteTo and ReadFrom", func(t *testing.T) {
+		trie := newBinaryTestTrie(t)
+
+		buf := new(bytes.Buffer)
+		written, err := trie.WriteTo(buf)
+		require.Nil(t, err)
+		require.Equal(t, int64(buf.Len()), written)
+
+		restored := NewTrie()
+		read, err := restored.ReadFrom(buf)
+		require.Nil(t, err)
+		require.Equal(t, written, read)
+		require.Equal(t, "/catalog/:category", restored.Search("/catalog/shoes", new(Params)).String())
+	})
+
+	t.Run("should reject corrupted snapshots and keep the trie untouched", func(t *testing.T) {
+		data, err := newBinaryTestTrie(t).MarshalBinary()
+		require.Nil(t, err)
+
+		corrupted := bytes.Clone(data)
+		corrupted[len(corrupted)/2] ^= 0xff
+
+		unsupported := bytes.Clone(data)
+		binary.BigEndian.PutUint16(unsupported[len(binaryMagic):], binaryVersion+1)
+
+		for name, bad := range map[string][]byte{
+			"corrupted":   corrupted,
+			"truncated":   data[:len(data)-1],
+			"unsupported": unsupported,
+			"empty":       nil,
+			"bad magic":   append([]byte("XXXX"), data[4:]...),
+		} {
+			trie := NewTrie()
+			require.Nil(t, trie.Insert("/kept"))
+
+			require.ErrorIs(t, trie.UnmarshalBinary(bad), ErrInvalidBinary, name)
+			require.Equal(t, "/kept", searchKey(trie, "/kept"), name)
+		}
+	})
+}
@@ -0,0 +1,321 @@
+package radixtrie
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// refNode and refTrie are the trie without the edge compression (a node per segment),
+// the reference for the compressed `Trie`.
+type refNode struct {
+	parent *refNode
+
+	children               map[string]*refNode
+	childNamedParameter    bool
+	childWildcardParameter bool
+
+	paramKeys []string
+	end       bool
+	key       string
+	staticKey string
+}
+
+type refTrie struct {
+	root            *refNode
+	hasRootWildcard bool
+	hasRootSlash    bool
+}
+
+func newRefTrie() *refTrie {
+	return &refTrie{root: &refNode{}}
+}
+
+func (n *refNode) closestParentWildcard() *refNode {
+	for n = n.parent; n != nil; n = n.parent {
+		if n.childWildcardParameter {
+			return n.children[WildcardParamStart]
+		}
+	}
+
+	return nil
+}
+
+// insert reports false if the pattern resolves to a node which is already taken.
+func (t *refTrie) insert(key string) bool {
+	n := t.root
+	var paramKeys []string
+
+	for _, s := range slowPathSplit(key) {
+		switch s[0] {
+		case ParamStart[0]:
+			paramKeys = append(paramKeys, s[1:])
+			n.childNamedParameter = true
+			s = ParamStart
+		case WildcardParamStart[0]:
+			paramKeys = append(paramKeys, s[1:])
+			n.childWildcardParameter = true
+			s = WildcardParamStart
+			if n == t.root {
+				t.hasRootWildcard = true
+			}
+		}
+
+		child := n.children[s]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[string]*refNode)
+			}
+			child = &refNode{parent: n}
+			n.children[s] = child
+		}
+		n = child
+	}
+
+	if n.end {
+		return false
+	}
+
+	if key == pathSep {
+		t.hasRootSlash = true
+	}
+
+	n.paramKeys = paramKeys
+	n.key = key
+	n.staticKey = resolveStaticPart(key)
+	n.end = true
+
+	return true
+}
+
+func (t *refTrie) search(q string, params ParamsSetter) *refNode {
+	q, _ = splitURL(q)
+	end := len(q)
+
+	if end == 0 || (end == 1 && q[0] == pathSepRune) {
+		if t.hasRootSlash {
+			return t.root.children[pathSep]
+		} else if t.hasRootWildcard {
+			return t.root.children[WildcardParamStart]
+		}
+
+		return nil
+	}
+
+	n := t.root
+	start := 1
+	i := 1
+	var paramValues []string
+
+	for {
+		if i == end || q[i] == pathSepRune {
+			if child := n.children[q[start:i]]; child != nil && isStaticLabel(q[start:i]) {
+				n = child
+			} else if n.childNamedParameter {
+				n = n.children[ParamStart]
+				paramValues = append(paramValues, q[start:i])
+			} else if n.childWildcardParameter {
+				n = n.children[WildcardParamStart]
+				paramValues = append(paramValues, q[start:])
+				break
+			} else {
+				if n = n.closestParentWildcard(); n != nil {
+					params.Set(n.paramKeys[0], q[len(n.staticKey):])
+					return n
+				}
+
+				return nil
+			}
+
+			if i == end {
+				break
+			}
+
+			i++
+			start = i
+			continue
+		}
+
+		i++
+	}
+
+	if !n.end {
+		if n = n.closestParentWildcard(); n != nil {
+			params.Set(n.paramKeys[0], q[len(n.staticKey):])
+			return n
+		}
+
+		if t.hasRootWildcard {
+			n = t.root.children[WildcardParamStart]
+			params.Set(n.paramKeys[0], q[1:])
+			return n
+		}
+
+		return nil
+	}
+
+	for i, paramValue := range paramValues {
+		if len(n.paramKeys) > i {
+			params.Set(n.paramKeys[i], paramValue)
+		}
+	}
+
+	return n
+}
+
+func randomPattern(r *rand.Rand) string {
+	if r.Intn(20) == 0 {
+		return pathSep
+	}
+
+	segments := make([]string, 1+r.Intn(5))
+	for i := range segments {
+		switch n := r.Intn(10); {
+		case n < 7:
+			segments[i] = string(rune('a' + r.Intn(3)))
+		case n < 9 || i != len(segments)-1:
+			segments[i] = fmt.Sprintf(":p%d", i)
+		default:
+			segments[i] = fmt.Sprintf("*w%d", i)
+		}
+	}
+
+	pattern := pathSep + strings.Join(segments, pathSep)
+	if r.Intn(10) == 0 && segments[len(segments)-1][0] != WildcardParamStart[0] {
+		pattern += pathSep
+	}
+
+	return pattern
+}
+
+// queryAlphabet are the segments of the random queries,
+// the parameter markers must be matched as the values, not as the parameter nodes.
+var queryAlphabet = []string{"a", "b", "c", "d", ParamStart, WildcardParamStart}
+
+func randomQuery(r *rand.Rand) string {
+	segments := make([]string, r.Intn(7))
+	for i := range segments {
+		segments[i] = queryAlphabet[r.Intn(len(queryAlphabet))]
+		if r.Intn(30) == 0 {
+			segments[i] = ""
+		}
+	}
+
+	q := pathSep + strings.Join(segments, pathSep)
+	if len(segments) > 0 && r.Intn(10) == 0 {
+		q += pathSep
+	}
+
+	return q
+}
+
+func requireSameSearch(t *testing.T, trie *Trie, ref *refTrie, q string) {
+	t.Helper()
+
+	var expectedKey, actualKey string
+	expectedParams, actualParams := new(Params), new(Params)
+
+	if n := ref.search(q, expectedParams); n != nil {
+		expectedKey = n.key
+	}
+	if n := trie.Search(q, actualParams); n != nil {
+		actualKey = n.key
+	}
+	require.Equal(t, expectedKey, actualKey, "query %q", q)
+	require.Equal(t, expectedParams.Map(), actualParams.Map(), "query %q", q)
+
+	frozenParams := new(Params)
+	if n := trie.Freeze().Search(q, frozenParams); n != nil {
+		require.Equal(t, expectedKey, n.key, "frozen query %q", q)
+	} else {
+		require.Empty(t, expectedKey, "frozen query %q", q)
+	}
+	require.Equal(t, expectedParams.Map(), frozenParams.Map(), "frozen query %q", q)
+}
+
+func Test_Compression(t *testing.T) {
+	t.Run("should compress static chains into a single edge", func(t *testing.T) {
+		trie := NewTrie()
+		require.Nil(t, trie.Insert("/a/b/c/d"))
+		require.Equal(t, "a/b/c/d", trie.root.getChild("a").label)
+
+		require.Nil(t, trie.Insert("/a/b/x/:id"))
+		ab := trie.root.getChild("a")
+		require.Equal(t, "a/b", ab.label)
+		require.Equal(t, "c/d", ab.getChild("c").label)
+		require.Equal(t, "x", ab.getChild("x").label)
+
+		require.Nil(t, trie.Insert("/a"))
+		require.Equal(t, "a", trie.root.getChild("a").label)
+		require.Equal(t, "b", trie.root.getChild("a").getChild("b").label)
+
+		require.True(t, trie.Delete("/a"))
+		require.True(t, trie.Delete("/a/b/x/:id"))
+		require.Equal(t, "a/b/c/d", trie.root.getChild("a").label)
+		require.Len(t, trie.root.children, 1)
+	})
+
+	t.Run("should not resolve patterns in the middle of an edge", func(t *testing.T) {
+		trie := NewTrie()
+		require.Nil(t, trie.Insert("/a/b/c"))
+
+		require.False(t, trie.Delete("/a/b"))
+		require.False(t, trie.Update("/a"))
+		require.Equal(t, "", searchKey(trie, "/a/b"))
+		require.Equal(t, "", searchKey(trie, "/a/b/x"))
+		require.Equal(t, "", searchKey(trie, "/a/bc"))
+		require.Equal(t, "/a/b/c", searchKey(trie, "/a/b/c"))
+
+		require.Equal(t, []string{"/a/b/c"}, trie.Autocomplete("/a/b", nil))
+		require.False(t, trie.HasPrefix("/a/x"))
+	})
+
+	t.Run("should match the uncompressed trie", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+
+		for round := 0; round < 300; round++ {
+			trie, ref := NewTrie(), newRefTrie()
+			var patterns []string
+
+			for i := 0; i < 1+r.Intn(30); i++ {
+				pattern := randomPattern(r)
+				inserted := ref.insert(pattern)
+				err := trie.Insert(pattern)
+				require.Equal(t, inserted, err == nil, "pattern %q: %v", pattern, err)
+				if inserted {
+					patterns = append(patterns, pattern)
+				}
+			}
+
+			for i := 0; i < 50; i++ {
+				requireSameSearch(t, trie, ref, randomQuery(r))
+			}
+
+			// the deletes must leave the same trie as the one built without the deleted patterns.
+			r.Shuffle(len(patterns), func(i, j int) { patterns[i], patterns[j] = patterns[j], patterns[i] })
+			deleted := r.Intn(len(patterns) + 1)
+			for _, pattern := range patterns[:deleted] {
+				require.True(t, trie.Delete(pattern), "pattern %q", pattern)
+			}
+
+			ref = newRefTrie()
+			for _, pattern := range patterns[deleted:] {
+				require.True(t, ref.insert(pattern))
+			}
+
+			for i := 0; i < 50; i++ {
+				requireSameSearch(t, trie, ref, randomQuery(r))
+			}
+
+			data, err := trie.MarshalBinary()
+			require.Nil(t, err)
+			decoded := NewTrie()
+			require.Nil(t, decoded.UnmarshalBinary(data))
+			for i := 0; i < 20; i++ {
+				requireSameSearch(t, decoded, ref, randomQuery(r))
+			}
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package radixtrie
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	constraintStart = '<'
+	constraintEnd   = '>'
+
+	constraintInt  = "int"
+	constraintEnum = "enum:"
+)
+
+// constraint restricts the values of a named parameter, declared as :name<spec>:
+//
+//	:id<int>          - a decimal integer, e.g. 42 or -1
+//	:lang<enum:ru,en> - one of the comma separated values
+//	:slug<[a-z0-9-]+> - anything else is a regular expression which must match the whole segment
+//
+// The regular expressions can't contain slashes and question marks.
+type constraint struct {
+	spec   string
+	isInt  bool
+	values []string
+	re     *regexp.Regexp
+}
+
+func parseConstraint(spec string) (*constraint, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("empty constraint")
+	}
+
+	c := &constraint{spec: spec}
+	switch {
+	case spec == constraintInt:
+		c.isInt = true
+	case strings.HasPrefix(spec, constraintEnum):
+		c.values = strings.Split(spec[len(constraintEnum):], ",")
+		for _, v := range c.values {
+			if v == "" {
+				return nil, fmt.Errorf("constraint %q: empty enum value", spec)
+			}
+		}
+	default:
+		if strings.ContainsAny(spec, QueryStart+string(fragmentStart)) {
+			return nil, fmt.Errorf("constraint %q: regular expression contains ? or #", spec)
+		}
+
+		re, err := regexp.Compile("^(?:" + spec + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("constraint %q: %w", spec, err)
+		}
+		c.re = re
+	}
+
+	return c, nil
+}
+
+// match reports whether the path "segment" satisfies the constraint.
+func (c *constraint) match(segment string) bool {
+	switch {
+	case c.isInt:
+		s := strings.TrimPrefix(segment, "-")
+		if s == "" {
+			return false
+		}
+
+		for i := 0; i < len(s); i++ {
+			if s[i] < '0' || s[i] > '9' {
+				return false
+			}
+		}
+
+		_, err := strconv.ParseInt(segment, 10, 64)
+		return err == nil
+	case c.values != nil:
+		for _, v := range c.values {
+			if v == segment {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return c.re.MatchString(segment)
+	}
+}
+
+// splitConstraint splits the parameter declaration (without : or *) into its name and constraint spec,
+// ok is false if the declaration has an unclosed or empty constraint.
+func splitConstraint(param string) (name, spec string, ok bool) {
+	i := strings.IndexByte(param, constraintStart)
+	if i == -1 {
+		return param, "", true
+	}
+
+	if len(param)-i < 3 || param[len(param)-1] != constraintEnd {
+		return param, "", false
+	}
+
+	return param[:i], param[i+1 : len(param)-1], true
+}
+
+// String returns the spec of the constraint, empty for nil.
+func (c *constraint) String() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.spec
+}
+
+// namedChild returns the named parameter child if its constraint, if any, accepts the "segment".
+func (n *Node) namedChild(segment string) *Node {
+	if !n.childNamedParameter {
+		return nil
+	}
+
+	child := n.getChild(ParamStart)
+	if child.constraint != nil && !child.constraint.match(segment) {
+		return nil
+	}
+
+	return child
+}
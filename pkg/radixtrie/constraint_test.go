@@ -0,0 +1,117 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_Constraint(t *testing.T) {
+	tests := []struct {
+		spec    string
+		match   []string
+		noMatch []string
+	}{
+		{"int", []string{"0", "42", "-1"}, []string{"", "-", "1.5", "+1", "sale", "99999999999999999999"}},
+		{"enum:ru,en", []string{"ru", "en"}, []string{"", "de", "ru,en", "RU"}},
+		{"[a-z0-9-]+", []string{"red-shoes", "42"}, []string{"", "Red", "red_shoes", "a/b"}},
+		{"a|b", []string{"a", "b"}, []string{"ab", "xa"}},
+	}
+
+	for _, tt := range tests {
+		c, err := parseConstraint(tt.spec)
+		require.Nil(t, err, tt.spec)
+
+		for _, s := range tt.match {
+			require.True(t, c.match(s), "%s: %q", tt.spec, s)
+		}
+		for _, s := range tt.noMatch {
+			require.False(t, c.match(s), "%s: %q", tt.spec, s)
+		}
+	}
+}
+
+func Test_ConstrainedSearch(t *testing.T) {
+	trie := NewTrie()
+	for _, pattern := range []string{
+		"/product/:id<int>",
+		"/product/*rest",
+		"/catalog/*any",
+		"/catalog/:lang<enum:ru,en>/about",
+		"/blog/:slug<[a-z0-9-]+>",
+		"/p/:id<int>/edit",
+		"/:lang<enum:ru,en>",
+		"/*path",
+	} {
+		require.Nil(t, trie.Insert(pattern))
+	}
+
+	data, err := trie.MarshalBinary()
+	require.Nil(t, err)
+	decoded := NewTrie()
+	require.Nil(t, decoded.UnmarshalBinary(data))
+
+	tests := []struct {
+		url     string
+		pattern string
+		params  map[string]string
+	}{
+		{"/product/42", "/product/:id<int>", map[string]string{"id": "42"}},
+		{"/product/sale", "/product/*rest", map[string]string{"rest": "sale"}},
+		{"/catalog/ru/about", "/catalog/:lang<enum:ru,en>/about", map[string]string{"lang": "ru"}},
+		{"/catalog/de/about", "/catalog/*any", map[string]string{"any": "de/about"}},
+		{"/blog/red-shoes", "/blog/:slug<[a-z0-9-]+>", map[string]string{"slug": "red-shoes"}},
+		{"/blog/Red", "/*path", map[string]string{"path": "blog/Red"}},
+		{"/en", "/:lang<enum:ru,en>", map[string]string{"lang": "en"}},
+		{"/de", "/*path", map[string]string{"path": "de"}},
+		// the parameter markers in the url are values, not the parameter nodes.
+		{"/product/:", "/product/*rest", map[string]string{"rest": ":"}},
+		{"/product/*", "/product/*rest", map[string]string{"rest": "*"}},
+		{"/p/:/edit", "/*path", map[string]string{"path": "p/:/edit"}},
+		{"/catalog/:/about", "/catalog/*any", map[string]string{"any": ":/about"}},
+	}
+
+	for _, tt := range tests {
+		for _, trie := range []*Trie{trie, decoded} {
+			p := new(Params)
+			n := trie.Search(tt.url, p)
+			require.NotNil(t, n, tt.url)
+			require.Equal(t, tt.pattern, n.String(), tt.url)
+			require.Equal(t, tt.params, p.Map(), tt.url)
+		}
+
+		p := new(Params)
+		fn := trie.Freeze().Search(tt.url, p)
+		require.Equal(t, tt.pattern, fn.String(), tt.url)
+		require.Equal(t, tt.params, p.Map(), tt.url)
+	}
+}
+
+func Test_ConstrainedInsert(t *testing.T) {
+	t.Run("should reject different constraints at the same position", func(t *testing.T) {
+		trie := NewTrie()
+		require.Nil(t, trie.Insert("/product/:id<int>/reviews"))
+		require.Nil(t, trie.Insert("/product/:num<int>/photos"))
+
+		var conflict *ConflictError
+		require.ErrorAs(t, trie.Insert("/product/:slug"), &conflict)
+		require.Equal(t, "/product/:id<int>/reviews", conflict.Existing)
+		require.ErrorAs(t, trie.Insert("/product/:id<enum:a,b>/reviews"), &conflict)
+
+		require.True(t, trie.Delete("/product/:id<int>/reviews"))
+		require.True(t, trie.Delete("/product/:num<int>/photos"))
+		require.Nil(t, trie.Insert("/product/:slug"))
+	})
+
+	t.Run("should reject invalid constraints", func(t *testing.T) {
+		for _, pattern := range []string{"/a/:id<int", "/a/:id<>", "/a/*rest<int>", "/a/:x<enum:>", "/a/:x<enum:a,>", "/a/:x<[a->", "/a/:x<a+?>"} {
+			require.NotNil(t, NewTrie().Insert(pattern), pattern)
+			require.NotNil(t, ValidatePattern(pattern), pattern)
+		}
+
+		require.Nil(t, ValidatePattern("/a/:id<int>/:slug<[a-z]+>/*rest"))
+	})
+
+	t.Run("should return param keys without constraints", func(t *testing.T) {
+		require.Equal(t, []string{"id", "slug"}, ParamKeys("/a/:id<int>/:slug<[a-z]+>"))
+	})
+}
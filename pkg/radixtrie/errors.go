@@ -0,0 +1,25 @@
+package radixtrie
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnknownPattern is returned by `Trie.URL` when the pattern was not inserted.
+	ErrUnknownPattern = errors.New("unknown pattern")
+	// ErrMissingParam is returned by `Node.URL` when a parameter of the pattern has no value.
+	ErrMissingParam = errors.New("missing parameter")
+	// ErrInvalidParam is returned by `Node.URL` when a value does not satisfy the parameter's constraint.
+	ErrInvalidParam = errors.New("invalid parameter")
+)
+
+// ConflictError is returned by `Insert` when two patterns resolve to the same node.
+type ConflictError struct {
+	Pattern  string // the pattern which was rejected.
+	Existing string // the pattern which was inserted before.
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("pattern %q conflicts with already inserted %q", e.Pattern, e.Existing)
+}
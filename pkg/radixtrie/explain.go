@@ -0,0 +1,174 @@
+package radixtrie
+
+// StepKind is the kind of a `Step` of the search, see `Trie.Explain`.
+type StepKind string
+
+const (
+	// StepStatic is a static child tried for the segment(s).
+	StepStatic StepKind = "static"
+	// StepNamed is a named parameter child tried for the segment, not matched if its constraint rejects the segment.
+	StepNamed StepKind = "named"
+	// StepWildcard is a wildcard child which takes the rest of the url.
+	StepWildcard StepKind = "wildcard"
+	// StepQuery is a query variant of the path node tried for the raw query.
+	StepQuery StepKind = "query"
+	// StepEnd is the end of the url, matched if the node is a pattern.
+	StepEnd StepKind = "end"
+	// StepMiss is a node which none of the children matches the segment.
+	StepMiss StepKind = "miss"
+	// StepClosestWildcard is the fallback to the closest parent wildcard.
+	StepClosestWildcard StepKind = "closest-wildcard"
+	// StepRootWildcard is the fallback to the root wildcard.
+	StepRootWildcard StepKind = "root-wildcard"
+	// StepBacktrack is the return to the node after its child failed, see `Trie.SetBacktracking`.
+	StepBacktrack StepKind = "backtrack"
+)
+
+// Step is a single step of the search.
+type Step struct {
+	Kind StepKind
+	// Segment is the part of the url the step is about,
+	// the rest of the url for wildcards and the raw query for queries.
+	Segment string
+	// Edge is the label of the node, with the constraint of a named parameter, e.g. ":<int>",
+	// or the canonical query of a query variant, e.g. "?color".
+	Edge string
+	// Pattern is the pattern of the node, if it's a final one.
+	Pattern string
+	Matched bool
+}
+
+// tracer records the steps of the search, the searches take a nil one
+// outside of `Explain`, so the tracing costs a nil check.
+type tracer struct {
+	steps []Step
+}
+
+func (tr *tracer) step(kind StepKind, segment string, n *Node, matched bool) {
+	s := Step{Kind: kind, Segment: segment, Matched: matched}
+	if n != nil {
+		s.Edge = n.label
+		if n.query != nil {
+			s.Edge = QueryStart + n.query.canonical
+		} else if n.constraint != nil {
+			s.Edge += string(constraintStart) + n.constraint.spec + string(constraintEnd)
+		}
+
+		if n.end || n.query != nil {
+			s.Pattern = n.key
+		}
+	}
+
+	tr.steps = append(tr.steps, s)
+}
+
+// traceNamed records the named parameter child of the "n" which constraint rejected the "segment", if any.
+func (tr *tracer) traceNamed(n *Node, segment string) {
+	if !n.childNamedParameter {
+		return
+	}
+
+	if named := n.getChild(ParamStart); named.constraint != nil && !named.constraint.match(segment) {
+		tr.step(StepNamed, segment, named, false)
+	}
+}
+
+// Explanation is the result of `Trie.Explain`.
+type Explanation struct {
+	// Node is the node `Search` returns, may be nil.
+	Node *Node
+	// Steps are the steps of the search in the order they were made.
+	Steps []Step
+	// Candidates are all the patterns which match the url, in the priority order
+	// of the backtracking search: static, named and then wildcard children first.
+	// The first of them is the result of the backtracking search,
+	// the default search may return another one or none, see `Trie.SetBacktracking`.
+	Candidates []*Node
+}
+
+// Explain searches the "q" like `Search` does and records every step of the search
+// with all the patterns which could have matched the "q".
+// It is slow and allocates, it's meant for debugging the registered patterns.
+func (t *Trie) Explain(q string, params ParamsSetter) *Explanation {
+	tr := new(tracer)
+	e := &Explanation{Node: t.search(q, params, tr)}
+	e.Steps = tr.steps
+	e.Candidates = t.candidates(q)
+
+	return e
+}
+
+// candidates returns all the nodes which match the "q", static, named and wildcard children first.
+func (t *Trie) candidates(q string) []*Node {
+	q, rawQuery := splitURL(q)
+	q, vq := foldPath(q, t.foldCase)
+	c := &collector{q: q, vq: vq, rawQuery: rawQuery, ignore: t.queryIgnore()}
+
+	if q == "" || q == pathSep {
+		if n := t.root.getChild(pathSep); n != nil {
+			c.accept(n)
+		}
+
+		if n := t.root.getChild(WildcardParamStart); n != nil {
+			c.accept(n)
+		}
+
+		return c.nodes
+	}
+
+	c.collect(t.root, 1)
+	return c.nodes
+}
+
+// collector is the exhaustive backtracking search of the `candidates`.
+type collector struct {
+	q        string
+	vq       string
+	rawQuery string
+	ignore   QueryIgnoreFunc
+	nodes    []*Node
+}
+
+func (c *collector) collect(n *Node, pos int) {
+	end := len(c.q)
+	if pos >= end {
+		c.accept(n)
+		return
+	}
+
+	i := pos
+	for i < end && c.q[i] != pathSepRune {
+		i++
+	}
+	segment, value := c.q[pos:i], c.vq[pos:i]
+
+	if child := n.getChild(segment); child != nil && isStaticLabel(child.label) {
+		rest := child.label[len(segment):]
+		if rest == "" || hasSegmentsPrefix(c.q[i:], rest) {
+			c.collect(child, nextSegment(c.q, i+len(rest)))
+		}
+	}
+
+	if value != "" {
+		if named := n.namedChild(value); named != nil {
+			c.collect(named, nextSegment(c.q, i))
+		}
+	}
+
+	if n.childWildcardParameter {
+		c.accept(n.getChild(WildcardParamStart))
+	}
+}
+
+// accept adds the matching query variants of the node and the node itself if it's a pattern.
+func (c *collector) accept(n *Node) {
+	for _, v := range n.queries {
+		if _, _, ok := v.query.match(c.rawQuery, c.ignore); ok {
+			c.nodes = append(c.nodes, v)
+		}
+	}
+
+	if n.end {
+		c.nodes = append(c.nodes, n)
+	}
+}
@@ -0,0 +1,158 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func explainPatterns(nodes []*Node) []string {
+	patterns := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		patterns = append(patterns, n.String())
+	}
+
+	return patterns
+}
+
+func Test_Explain(t *testing.T) {
+	trie := NewTrie()
+	for _, p := range []string{
+		"/",
+		"/*any",
+		"/a/static/x",
+		"/a/static/deep/x",
+		"/a/:p/y",
+		"/a/:p/:q/z",
+		"/a/*rest",
+		"/b/:id<int>/x",
+		"/c/:p?color",
+		"/c/:p",
+	} {
+		require.Nil(t, trie.Insert(p))
+	}
+
+	t.Run("should record the steps of the static and named children", func(t *testing.T) {
+		p := new(Params)
+		e := trie.Explain("/a/static/x", p)
+		require.Equal(t, "/a/static/x", e.Node.String())
+		require.Equal(t, []Step{
+			{Kind: StepStatic, Segment: "a", Edge: "a", Matched: true},
+			{Kind: StepStatic, Segment: "static", Edge: "static", Matched: true},
+			{Kind: StepStatic, Segment: "x", Edge: "x", Pattern: "/a/static/x", Matched: true},
+			{Kind: StepEnd, Edge: "x", Pattern: "/a/static/x", Matched: true},
+		}, e.Steps)
+		require.Equal(t, []string{"/a/static/x", "/a/*rest", "/*any"}, explainPatterns(e.Candidates))
+	})
+
+	t.Run("should record the fallback to the closest wildcard", func(t *testing.T) {
+		p := new(Params)
+		e := trie.Explain("/a/static/y", p)
+		require.Equal(t, "/a/*rest", e.Node.String())
+		require.Equal(t, map[string]string{"rest": "static/y"}, p.Map())
+		require.Equal(t, []Step{
+			{Kind: StepStatic, Segment: "a", Edge: "a", Matched: true},
+			{Kind: StepStatic, Segment: "static", Edge: "static", Matched: true},
+			{Kind: StepMiss, Segment: "y", Edge: "static"},
+			{Kind: StepClosestWildcard, Segment: "static/y", Edge: "*", Pattern: "/a/*rest", Matched: true},
+		}, e.Steps)
+		// the backtracking search would have matched the named parameter.
+		require.Equal(t, []string{"/a/:p/y", "/a/*rest", "/*any"}, explainPatterns(e.Candidates))
+	})
+
+	t.Run("should record the rejected constraint", func(t *testing.T) {
+		p := new(Params)
+		e := trie.Explain("/b/x/x", p)
+		require.Equal(t, "/*any", e.Node.String())
+		require.Equal(t, []Step{
+			{Kind: StepStatic, Segment: "b", Edge: "b", Matched: true},
+			{Kind: StepNamed, Segment: "x", Edge: ":<int>"},
+			{Kind: StepMiss, Segment: "x", Edge: "b"},
+			{Kind: StepClosestWildcard, Segment: "b/x/x", Edge: "*", Pattern: "/*any", Matched: true},
+		}, e.Steps)
+		require.Equal(t, []string{"/*any"}, explainPatterns(e.Candidates))
+	})
+
+	t.Run("should record the query variants", func(t *testing.T) {
+		e := trie.Explain("/c/shoes?size=42", new(Params))
+		require.Equal(t, "/c/:p", e.Node.String())
+		require.Equal(t, []Step{
+			{Kind: StepStatic, Segment: "c", Edge: "c", Matched: true},
+			{Kind: StepNamed, Segment: "shoes", Edge: ":", Pattern: "/c/:p", Matched: true},
+			{Kind: StepQuery, Segment: "size=42", Edge: "?color", Pattern: "/c/:p?color"},
+			{Kind: StepEnd, Edge: ":", Pattern: "/c/:p", Matched: true},
+		}, e.Steps)
+		require.Equal(t, []string{"/c/:p", "/*any"}, explainPatterns(e.Candidates))
+
+		e = trie.Explain("/c/shoes?color=red", new(Params))
+		require.Equal(t, "/c/:p?color", e.Node.String())
+		require.Equal(t, []string{"/c/:p?color", "/c/:p", "/*any"}, explainPatterns(e.Candidates))
+	})
+
+	t.Run("should record the root", func(t *testing.T) {
+		e := trie.Explain("/", new(Params))
+		require.Equal(t, "/", e.Node.String())
+		require.Equal(t, []Step{{Kind: StepEnd, Segment: "/", Edge: "/", Pattern: "/", Matched: true}}, e.Steps)
+		require.Equal(t, []string{"/", "/*any"}, explainPatterns(e.Candidates))
+
+		wildcard := NewTrie()
+		require.Nil(t, wildcard.Insert("/*any"))
+		e = wildcard.Explain("/", new(Params))
+		require.Equal(t, "/*any", e.Node.String())
+		require.Equal(t, []Step{{Kind: StepRootWildcard, Segment: "/", Edge: "*", Pattern: "/*any", Matched: true}}, e.Steps)
+		require.Equal(t, []string{"/*any"}, explainPatterns(e.Candidates))
+	})
+
+	t.Run("should record the backtracking", func(t *testing.T) {
+		trie.SetBacktracking(true)
+		defer trie.SetBacktracking(false)
+
+		p := new(Params)
+		e := trie.Explain("/a/static/y", p)
+		require.Equal(t, "/a/:p/y", e.Node.String())
+		require.Equal(t, map[string]string{"p": "static"}, p.Map())
+		require.Equal(t, []Step{
+			{Kind: StepStatic, Segment: "a", Edge: "a", Matched: true},
+			{Kind: StepStatic, Segment: "static", Edge: "static", Matched: true},
+			{Kind: StepMiss, Segment: "y", Edge: "static"},
+			{Kind: StepBacktrack, Segment: "static", Edge: "a"},
+			{Kind: StepNamed, Segment: "static", Edge: ":", Matched: true},
+			{Kind: StepStatic, Segment: "y", Edge: "y", Pattern: "/a/:p/y", Matched: true},
+			{Kind: StepEnd, Edge: "y", Pattern: "/a/:p/y", Matched: true},
+		}, e.Steps)
+	})
+}
+
+func Test_ExplainCandidates(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		trie := NewTrie()
+		trie.SetBacktracking(true)
+		var patterns []string
+		for j := 0; j < 2+r.Intn(30); j++ {
+			pattern := randomBacktrackPattern(r)
+			if trie.Insert(pattern) == nil {
+				patterns = append(patterns, pattern)
+			}
+		}
+
+		for j := 0; j < 100; j++ {
+			url := randomBacktrackURL(r)
+			e := trie.Explain(url, new(Params))
+
+			var expected []string
+			for _, pattern := range patterns {
+				if _, _, ok := bruteMatchPattern(pattern, strings.Split(url[1:], pathSep)); ok {
+					expected = append(expected, pattern)
+				}
+			}
+
+			require.ElementsMatch(t, expected, explainPatterns(e.Candidates), url)
+			if e.Node != nil {
+				require.Equal(t, e.Node, e.Candidates[0], url)
+			}
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package radixtrie
+
+import (
+	"bufio"
+	"bytes"
+	"sort"
+)
+
+// Frozen is a read-only, memory-compact representation of a `Trie`, see `Trie.Freeze`.
+//
+// Instead of a node per allocation with a children map, the nodes and their static child edges
+// are stored in flat arrays (the edges of a node are contiguous and sorted, so a child is found by binary search),
+// the segments and param keys are interned and the equal `SeoData` values are deduplicated.
+// Frozen's `Search` has the same semantics as the `Trie.Search`.
+type Frozen struct {
+	nodes     []FrozenNode
+	edges     []frozenEdge
+	paramKeys []string
+
+	hasRootWildcard bool
+	hasRootSlash    bool
+	ignoreQuery     QueryIgnoreFunc
+	backtracking    bool
+	foldCase        bool
+}
+
+// FrozenNode is a node of the `Frozen` trie.
+type FrozenNode struct {
+	parent     int32
+	named      int32 // the named parameter child or -1.
+	wildcard   int32 // the wildcard child or -1.
+	firstEdge  uint32
+	edgeCount  uint32
+	paramStart uint32
+	staticLen  uint32 // the length of the static part of the key, see `resolveStaticPart`.
+	paramCount uint16
+	queryCount uint16 // the query variants are the nodes from firstQuery.
+	firstQuery uint32
+	end        bool
+	key        string
+	query      *queryPattern
+	constraint *constraint // of the named parameter node.
+
+	Data      *SeoData
+	Localized map[string]*SeoData
+	Redirect  *Redirect
+}
+
+type frozenEdge struct {
+	label string // the first segment of the edge.
+	rest  string // the rest of a compressed edge, e.g. "/b/c" of "a/b/c".
+	child uint32
+}
+
+// String returns the key, which is the path pattern.
+func (n *FrozenNode) String() string {
+	return n.key
+}
+
+// IsEnd returns true if this node is a final path, has a key.
+func (n *FrozenNode) IsEnd() bool {
+	return n.end
+}
+
+// CanonicalQuery is the `Node.CanonicalQuery` for the frozen trie.
+func (n *FrozenNode) CanonicalQuery(params ParamsGetter) string {
+	if n == nil || n.query == nil {
+		return ""
+	}
+
+	return n.query.canonicalQuery(params)
+}
+
+// Freeze returns a read-only copy of the trie, the trie itself is not modified
+// and can be dropped after that. The copy keeps the trie's query ignore rule
+// and search modes, see `SetQueryIgnore`, `SetBacktracking` and `SetCaseFolding`.
+func (t *Trie) Freeze() *Frozen {
+	f := &Frozen{
+		hasRootWildcard: t.hasRootWildcard,
+		hasRootSlash:    t.hasRootSlash,
+		ignoreQuery:     t.queryIgnore(),
+		backtracking:    t.backtracking,
+		foldCase:        t.foldCase,
+	}
+
+	strs := make(map[string]string)
+	intern := func(s string) string {
+		if v, ok := strs[s]; ok {
+			return v
+		}
+		strs[s] = s
+		return s
+	}
+
+	// the equal data values have the equal binary encoding, see `encoder.data`.
+	datas := make(map[string]*SeoData)
+	buf := new(bytes.Buffer)
+	e := &encoder{w: bufio.NewWriter(buf)}
+	dedup := func(d *SeoData) *SeoData {
+		if d == nil {
+			return nil
+		}
+
+		buf.Reset()
+		e.data(d)
+		_ = e.w.Flush()
+
+		if v, ok := datas[string(buf.Bytes())]; ok {
+			return v
+		}
+		datas[buf.String()] = d
+		return d
+	}
+
+	// breadth-first, so the static children of a node get contiguous indexes.
+	queue := []*Node{t.root}
+	f.nodes = append(f.nodes, FrozenNode{parent: -1})
+
+	for i := 0; i < len(queue); i++ {
+		n := queue[i]
+		fn := &f.nodes[i]
+		fn.named, fn.wildcard = -1, -1
+		fn.end = n.end
+		fn.key = n.key
+		fn.query = n.query
+		fn.constraint = n.constraint
+		fn.staticLen = uint32(len(n.staticKey))
+		fn.Data = dedup(n.Data)
+		if n.Localized != nil {
+			fn.Localized = make(map[string]*SeoData, len(n.Localized))
+			for locale, d := range n.Localized {
+				fn.Localized[locale] = dedup(d)
+			}
+		}
+		fn.Redirect = n.Redirect
+		fn.paramStart = uint32(len(f.paramKeys))
+		fn.paramCount = uint16(len(n.paramKeys))
+		for _, k := range n.paramKeys {
+			f.paramKeys = append(f.paramKeys, intern(k))
+		}
+
+		labels := make([]string, 0, len(n.children))
+		for s := range n.children {
+			labels = append(labels, s)
+		}
+		sort.Strings(labels)
+
+		fn.firstEdge = uint32(len(f.edges))
+		for _, s := range labels {
+			child := uint32(len(queue))
+			queue = append(queue, n.children[s])
+			f.nodes = append(f.nodes, FrozenNode{parent: int32(i)})
+			fn = &f.nodes[i] // the nodes slice may be reallocated.
+
+			switch s {
+			case ParamStart:
+				fn.named = int32(child)
+			case WildcardParamStart:
+				fn.wildcard = int32(child)
+			default:
+				label := n.children[s].label
+				f.edges = append(f.edges, frozenEdge{label: intern(s), rest: intern(label[len(s):]), child: child})
+				fn.edgeCount++
+			}
+		}
+
+		fn.firstQuery = uint32(len(queue))
+		for _, v := range n.queries {
+			queue = append(queue, v)
+			f.nodes = append(f.nodes, FrozenNode{parent: int32(i)})
+			fn = &f.nodes[i]
+			fn.queryCount++
+		}
+	}
+
+	f.nodes = f.nodes[:len(f.nodes):len(f.nodes)]
+	f.edges = f.edges[:len(f.edges):len(f.edges)]
+	f.paramKeys = f.paramKeys[:len(f.paramKeys):len(f.paramKeys)]
+
+	return f
+}
+
+func (f *Frozen) child(n int32, s string) (int32, string) {
+	fn := &f.nodes[n]
+	edges := f.edges[fn.firstEdge : fn.firstEdge+fn.edgeCount]
+
+	i := sort.Search(len(edges), func(i int) bool { return edges[i].label >= s })
+	if i < len(edges) && edges[i].label == s {
+		return int32(edges[i].child), edges[i].rest
+	}
+
+	return -1, ""
+}
+
+func (f *Frozen) namedChild(n int32, segment string) int32 {
+	named := f.nodes[n].named
+	if named != -1 {
+		if c := f.nodes[named].constraint; c != nil && !c.match(segment) {
+			return -1
+		}
+	}
+
+	return named
+}
+
+func (f *Frozen) closestParentWildcard(n int32) int32 {
+	for n = f.nodes[n].parent; n != -1; n = f.nodes[n].parent {
+		if w := f.nodes[n].wildcard; w != -1 {
+			return w
+		}
+	}
+
+	return -1
+}
+
+func (f *Frozen) paramKey(n int32, i int) string {
+	return f.paramKeys[int(f.nodes[n].paramStart)+i]
+}
+
+func (f *Frozen) setClosestWildcardParam(n int32, q string, params ParamsSetter) *FrozenNode {
+	fn := &f.nodes[n]
+	params.Set(f.paramKey(n, 0), q[fn.staticLen:])
+	return fn
+}
+
+func (f *Frozen) matchQuery(n int32, rawQuery string, pathValues []string, params ParamsSetter) *FrozenNode {
+	fn := &f.nodes[n]
+	for v := fn.firstQuery; v < fn.firstQuery+uint32(fn.queryCount); v++ {
+		variant := &f.nodes[v]
+		values, seen, ok := variant.query.match(rawQuery, f.ignoreQuery)
+		if !ok {
+			continue
+		}
+
+		for i, paramValue := range pathValues {
+			if int(variant.paramCount) > i {
+				params.Set(f.paramKey(int32(v), i), paramValue)
+			}
+		}
+		variant.query.capture(&values, seen, params)
+
+		return variant
+	}
+
+	return nil
+}
+
+// Search is the `Trie.Search` for the frozen trie, see its documentation.
+func (f *Frozen) Search(q string, params ParamsSetter) *FrozenNode {
+	q, rawQuery := splitURL(q)
+	q, values := foldPath(q, f.foldCase)
+	end := len(q)
+
+	if end == 0 || (end == 1 && q[0] == pathSepRune) {
+		if f.hasRootSlash {
+			child, _ := f.child(0, pathSep)
+			if v := f.matchQuery(child, rawQuery, nil, params); v != nil {
+				return v
+			}
+
+			if f.nodes[child].end {
+				return &f.nodes[child]
+			}
+		}
+
+		if f.hasRootWildcard {
+			return &f.nodes[f.nodes[0].wildcard]
+		}
+
+		return nil
+	}
+
+	if f.backtracking {
+		return f.searchBacktracking(q, values, rawQuery, params)
+	}
+
+	var n int32
+	start := 1
+	i := 1
+	var paramValuesBuf [8]string
+	paramValues := paramValuesBuf[:0]
+
+	for {
+		if i == end || q[i] == pathSepRune {
+			if child, rest := f.child(n, q[start:i]); child != -1 {
+				if rest != "" {
+					if !hasSegmentsPrefix(q[i:], rest) {
+						if n = f.closestParentWildcard(child); n != -1 {
+							return f.setClosestWildcardParam(n, values, params)
+						}
+
+						return nil
+					}
+					i += len(rest)
+				}
+				n = child
+			} else if named := f.namedChild(n, values[start:i]); named != -1 {
+				n = named
+				paramValues = append(paramValues, values[start:i])
+			} else if wildcard := f.nodes[n].wildcard; wildcard != -1 {
+				n = wildcard
+				paramValues = append(paramValues, values[start:])
+				break
+			} else {
+				if n = f.closestParentWildcard(n); n != -1 {
+					return f.setClosestWildcardParam(n, values, params)
+				}
+
+				return nil
+			}
+
+			if i == end {
+				break
+			}
+
+			i++
+			start = i
+			continue
+		}
+
+		i++
+	}
+
+	if f.nodes[n].queryCount != 0 {
+		if v := f.matchQuery(n, rawQuery, paramValues, params); v != nil {
+			return v
+		}
+	}
+
+	if !f.nodes[n].end {
+		if n = f.closestParentWildcard(n); n != -1 {
+			return f.setClosestWildcardParam(n, values, params)
+		}
+
+		if f.hasRootWildcard {
+			n = f.nodes[0].wildcard
+			params.Set(f.paramKey(n, 0), values[1:])
+			return &f.nodes[n]
+		}
+
+		return nil
+	}
+
+	fn := &f.nodes[n]
+	for i, paramValue := range paramValues {
+		if int(fn.paramCount) > i {
+			params.Set(f.paramKey(n, i), paramValue)
+		}
+	}
+
+	return fn
+}
@@ -0,0 +1,184 @@
+package radixtrie
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"testing"
+)
+
+var frozenTestPatterns = []string{
+	"/",
+	"/*any",
+	"/about",
+	"/about/company/contacts",
+	"/catalog/:category",
+	"/catalog/:category/:brand/:model",
+	"/catalog/:category/*rest",
+	"/catalog/sale",
+	"/blog/*path",
+	"/blog/:year/static/:slug",
+	"/second/wild/*p",
+	"/second/wild/static/otherstatic/",
+	"/users/:id/profile",
+	"/x/:id<int>",
+}
+
+var frozenTestQueries = []string{
+	"/",
+	"",
+	"/about",
+	"/about/company",
+	"/about/company/contacts",
+	"/catalog/shoes",
+	"/catalog/sale",
+	"/catalog/shoes/nike/air",
+	"/catalog/shoes/red/42/sale",
+	"/blog/2025",
+	"/blog/2025/static/post",
+	"/blog/2025/static",
+	"/second/wild/static/otherstatic",
+	"/second/wild/static/otherstatic/random",
+	"/users/1/profile",
+	"/users/1",
+	"/unknown/page",
+	// the parameter markers in the url are values, not the parameter nodes.
+	"/:",
+	"/*",
+	"/x/:",
+	"/x/*",
+	"/blog/*",
+	"/catalog/:",
+	"/catalog/*/nike",
+	"/users/:/profile",
+}
+
+func Test_Freeze(t *testing.T) {
+	t.Run("should search the same way as the trie", func(t *testing.T) {
+		trie := NewTrie()
+		for _, pattern := range frozenTestPatterns {
+			title := pattern
+			require.Nil(t, trie.Insert(pattern, WithData(&SeoData{MetaTitle: &title})))
+		}
+
+		frozen := trie.Freeze()
+
+		for _, q := range frozenTestQueries {
+			expectedParams, params := new(Params), new(Params)
+			expected, n := trie.Search(q, expectedParams), frozen.Search(q, params)
+
+			if expected == nil {
+				require.Nil(t, n, q)
+				continue
+			}
+
+			require.NotNil(t, n, q)
+			require.Equal(t, expected.String(), n.String(), q)
+			require.Equal(t, expected.IsEnd(), n.IsEnd(), q)
+			require.Same(t, expected.Data, n.Data, q)
+			require.Equal(t, expectedParams.Map(), params.Map(), q)
+		}
+	})
+
+	t.Run("should deduplicate equal data", func(t *testing.T) {
+		trie := NewTrie()
+		require.Nil(t, trie.Insert("/a", WithData(&SeoData{MetaTitle: strPtr("same")})))
+		require.Nil(t, trie.Insert("/b", WithData(&SeoData{MetaTitle: strPtr("same")})))
+		require.Nil(t, trie.Insert("/c", WithData(&SeoData{MetaTitle: strPtr("other")})))
+
+		frozen := trie.Freeze()
+		a, b, c := frozen.Search("/a", new(Params)), frozen.Search("/b", new(Params)), frozen.Search("/c", new(Params))
+		require.Same(t, a.Data, b.Data)
+		require.NotSame(t, a.Data, c.Data)
+	})
+
+	t.Run("should not allocate on lookups", func(t *testing.T) {
+		frozen := newBenchTrie().Freeze()
+
+		for _, q := range benchQueries {
+			allocs := testing.AllocsPerRun(100, func() {
+				p := AcquireParams()
+				if frozen.Search(q, p) == nil {
+					t.Fatalf("%s is not found", q)
+				}
+				ReleaseParams(p)
+			})
+			require.Zero(t, allocs, q)
+		}
+	})
+}
+
+// newLargeTrie builds a catalog-like trie of "n" product urls.
+func newLargeTrie(n int) *Trie {
+	trie := NewTrie()
+	for i := 0; i < n; i++ {
+		title := fmt.Sprintf("Product %d", i%1000)
+		_ = trie.Insert(
+			fmt.Sprintf("/catalog/category-%d/brand-%d/product-%d", i%50, i%500, i),
+			WithData(&SeoData{MetaTitle: &title, MetaRobots: strPtr("index, follow")}),
+		)
+	}
+	_ = trie.Insert("/catalog/:category/:brand/*rest")
+
+	return trie
+}
+
+func heapAlloc() uint64 {
+	runtime.GC()
+	runtime.GC()
+
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+func BenchmarkFreezeMemory(b *testing.B) {
+	const routes = 100_000
+
+	b.Run("trie", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			before := heapAlloc()
+			trie := newLargeTrie(routes)
+			b.ReportMetric(float64(heapAlloc()-before)/routes, "bytes/route")
+			runtime.KeepAlive(trie)
+		}
+	})
+
+	b.Run("frozen", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			before := heapAlloc()
+			frozen := newLargeTrie(routes).Freeze()
+			b.ReportMetric(float64(heapAlloc()-before)/routes, "bytes/route")
+			runtime.KeepAlive(frozen)
+		}
+	})
+}
+
+func BenchmarkFrozenSearch(b *testing.B) {
+	const routes = 100_000
+
+	trie := newLargeTrie(routes)
+	frozen := trie.Freeze()
+	queries := []string{
+		"/catalog/category-7/brand-257/product-12757",
+		"/catalog/category-7/brand-257/unknown/product",
+	}
+
+	b.Run("trie", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p := AcquireParams()
+			trie.Search(queries[i%len(queries)], p)
+			ReleaseParams(p)
+		}
+	})
+
+	b.Run("frozen", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			p := AcquireParams()
+			frozen.Search(queries[i%len(queries)], p)
+			ReleaseParams(p)
+		}
+	})
+}
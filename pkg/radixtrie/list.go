@@ -0,0 +1,80 @@
+package radixtrie
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultListLimit is the page size of `Trie.List` when `ListOptions.Limit` is not positive.
+const DefaultListLimit = 100
+
+// ListOptions describes a page of `Trie.List`.
+type ListOptions struct {
+	// Prefix filters the keys by a raw string prefix, it can end in the middle
+	// of a segment or a parameter's name, e.g. "/cat" matches "/catalog/:category".
+	Prefix string
+	// After is the cursor, the page starts with the first key greater than it.
+	// Use the `ListPage.Next` of the previous page or empty for the first page.
+	After string
+	// Limit is the maximum number of the page's keys, see `DefaultListLimit`.
+	Limit int
+}
+
+// ListPage is a page of the lexically sorted keys returned by `Trie.List`.
+type ListPage struct {
+	Keys []string
+	// Next is the cursor of the next page, empty if this page is the last one.
+	Next string
+}
+
+// List returns a page of the inserted keys (including the query variants) which start with
+// the options' prefix, in a stable lexical order.
+// The first call after `Insert` or `Delete` builds the sorted index of the keys,
+// the next calls only search it, so it is cheap to page through large tries.
+// It is safe to call List concurrently as long as the trie is not modified.
+func (t *Trie) List(opts ListOptions) ListPage {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+
+	keys := t.sortedKeys()
+	i := sort.SearchStrings(keys, opts.Prefix)
+	if opts.After != "" {
+		if after := sort.Search(len(keys), func(i int) bool { return keys[i] > opts.After }); after > i {
+			i = after
+		}
+	}
+
+	page := ListPage{Keys: make([]string, 0)}
+	for ; i < len(keys) && strings.HasPrefix(keys[i], opts.Prefix); i++ {
+		if len(page.Keys) == limit {
+			page.Next = page.Keys[limit-1]
+			break
+		}
+
+		page.Keys = append(page.Keys, keys[i])
+	}
+
+	return page
+}
+
+// sortedKeys returns the lexically sorted keys of the trie, the result must not be modified.
+func (t *Trie) sortedKeys() []string {
+	t.keysMu.Lock()
+	defer t.keysMu.Unlock()
+
+	if t.keys == nil {
+		keys := t.root.appendKeys(make([]string, 0))
+		sort.Strings(keys)
+		t.keys = keys
+	}
+
+	return t.keys
+}
+
+func (t *Trie) dropKeys() {
+	t.keysMu.Lock()
+	t.keys = nil
+	t.keysMu.Unlock()
+}
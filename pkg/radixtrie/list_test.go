@@ -0,0 +1,90 @@
+package radixtrie
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func Test_List(t *testing.T) {
+	trie := NewTrie()
+	for _, p := range []string{
+		"/",
+		"/catalog",
+		"/catalog/:category",
+		"/catalog/:category?color",
+		"/catalog/:category/*rest",
+		"/cart",
+		"/brands/:brand<enum:nike,puma>",
+		"/*rest",
+	} {
+		require.Nil(t, trie.Insert(p))
+	}
+
+	t.Run("should match partial segments and dynamic keys", func(t *testing.T) {
+		page := trie.List(ListOptions{Prefix: "/ca"})
+		require.Equal(t, []string{
+			"/cart",
+			"/catalog",
+			"/catalog/:category",
+			"/catalog/:category/*rest",
+			"/catalog/:category?color",
+		}, page.Keys)
+		require.Empty(t, page.Next)
+
+		require.Equal(t, []string{"/brands/:brand<enum:nike,puma>"}, trie.List(ListOptions{Prefix: "/brands/:br"}).Keys)
+		require.Empty(t, trie.List(ListOptions{Prefix: "/unknown"}).Keys)
+		require.Len(t, trie.List(ListOptions{}).Keys, 8)
+	})
+
+	t.Run("should paginate with cursor", func(t *testing.T) {
+		var all []string
+		opts := ListOptions{Prefix: "/", Limit: 3}
+		for {
+			page := trie.List(opts)
+			require.LessOrEqual(t, len(page.Keys), 3)
+			all = append(all, page.Keys...)
+			if page.Next == "" {
+				break
+			}
+			opts.After = page.Next
+		}
+
+		expected := trie.root.Keys(nil)
+		sort.Strings(expected)
+		require.Equal(t, expected, all)
+	})
+
+	t.Run("should rebuild index after modifications", func(t *testing.T) {
+		require.Nil(t, trie.Insert("/catalog/sale"))
+		require.Contains(t, trie.List(ListOptions{Prefix: "/catalog/s"}).Keys, "/catalog/sale")
+
+		require.True(t, trie.Delete("/catalog/sale"))
+		require.Empty(t, trie.List(ListOptions{Prefix: "/catalog/s"}).Keys)
+	})
+
+	t.Run("should autocomplete partial segments", func(t *testing.T) {
+		require.Equal(t, []string{
+			"/catalog",
+			"/catalog/:category",
+			"/catalog/:category?color",
+			"/catalog/:category/*rest",
+		}, trie.Autocomplete("/catalog", DefaultKeysSorter))
+		require.Nil(t, trie.Autocomplete("/unknown", nil))
+	})
+}
+
+func Test_KeysSortOnce(t *testing.T) {
+	trie := NewTrie()
+	for i := 0; i < 50; i++ {
+		require.Nil(t, trie.Insert(fmt.Sprintf("/a/%d/b/%d", i%5, i)))
+	}
+
+	keys := trie.root.Keys(DefaultKeysSorter)
+	require.Len(t, keys, 50)
+	require.True(t, sort.SliceIsSorted(keys, func(i, j int) bool {
+		return strings.Count(keys[i], pathSep) < strings.Count(keys[j], pathSep)
+	}))
+}
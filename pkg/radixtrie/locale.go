@@ -0,0 +1,77 @@
+package radixtrie
+
+import (
+	"sort"
+)
+
+// WithLocaleData sets the node's data for the "locale", the empty locale sets the `Data` field.
+func WithLocaleData(locale string, data *SeoData) InsertOption {
+	return func(n *Node) {
+		if locale == "" {
+			n.Data = data
+			return
+		}
+
+		if n.Localized == nil {
+			n.Localized = make(map[string]*SeoData)
+		}
+		n.Localized[locale] = data
+	}
+}
+
+// InsertLocale inserts the "pattern" with the "data" of the "locale" (see `WithLocaleData`)
+// or adds the locale's data to the already inserted pattern, so the locales share the pattern's node.
+// It returns a `ConflictError` if the pattern already has data for the locale or a redirect,
+// or if it resolves to the node of another pattern, e.g. with other parameter names.
+func (t *Trie) InsertLocale(pattern, locale string, data *SeoData) error {
+	n := t.find(pattern)
+	if n == nil {
+		return t.Insert(pattern, WithLocaleData(locale, data))
+	}
+
+	if n.Redirect != nil || n.hasLocale(locale) {
+		return &ConflictError{Pattern: pattern, Existing: n.key}
+	}
+
+	WithLocaleData(locale, data)(n)
+	return nil
+}
+
+func (n *Node) hasLocale(locale string) bool {
+	if locale == "" {
+		return n.Data != nil
+	}
+
+	_, ok := n.Localized[locale]
+	return ok
+}
+
+// Locales returns the sorted locales of the node's `Localized` data.
+func (n *Node) Locales() []string {
+	locales := make([]string, 0, len(n.Localized))
+	for locale := range n.Localized {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// LocaleData returns the data of the first locale of the "chain" which the node has data for, and the locale.
+// The empty locale in the chain stands for the `Data` field. It returns nil if none of the locales has data.
+func (n *Node) LocaleData(chain []string) (*SeoData, string) {
+	for _, locale := range chain {
+		if locale == "" {
+			if n.Data != nil {
+				return n.Data, ""
+			}
+			continue
+		}
+
+		if d := n.Localized[locale]; d != nil {
+			return d, locale
+		}
+	}
+
+	return nil, ""
+}
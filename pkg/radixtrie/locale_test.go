@@ -0,0 +1,62 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_InsertLocale(t *testing.T) {
+	trie := NewTrie()
+	require.Nil(t, trie.InsertLocale("/catalog/:category", "", &SeoData{MetaTitle: strPtr("каталог")}))
+	require.Nil(t, trie.InsertLocale("/catalog/:category", "en", &SeoData{MetaTitle: strPtr("catalog")}))
+	require.Nil(t, trie.InsertLocale("/catalog/:category", "en-gb", &SeoData{MetaTitle: strPtr("catalogue")}))
+	require.Nil(t, trie.InsertLocale("/about", "en", &SeoData{MetaTitle: strPtr("about")}))
+
+	t.Run("should report conflicts", func(t *testing.T) {
+		var conflict *ConflictError
+		require.ErrorAs(t, trie.InsertLocale("/catalog/:category", "en", &SeoData{}), &conflict)
+		require.Equal(t, "/catalog/:category", conflict.Existing)
+
+		require.ErrorAs(t, trie.InsertLocale("/catalog/:slug", "de", &SeoData{}), &conflict)
+		require.Equal(t, "/catalog/:category", conflict.Existing)
+	})
+
+	t.Run("should resolve data by locale chain", func(t *testing.T) {
+		n := trie.Search("/catalog/shoes", new(Params))
+		require.Equal(t, []string{"en", "en-gb"}, n.Locales())
+
+		tests := []struct {
+			chain  []string
+			title  string
+			locale string
+		}{
+			{[]string{"en-gb", "en", ""}, "catalogue", "en-gb"},
+			{[]string{"en-us", "en", ""}, "catalog", "en"},
+			{[]string{"de", ""}, "каталог", ""},
+		}
+
+		for _, tt := range tests {
+			data, locale := n.LocaleData(tt.chain)
+			require.Equal(t, tt.title, *data.MetaTitle, tt.chain)
+			require.Equal(t, tt.locale, locale, tt.chain)
+		}
+
+		data, _ := trie.Search("/about", new(Params)).LocaleData([]string{"ru", ""})
+		require.Nil(t, data)
+	})
+
+	t.Run("should keep localized data in binary snapshot and frozen trie", func(t *testing.T) {
+		data, err := trie.MarshalBinary()
+		require.Nil(t, err)
+
+		decoded := NewTrie()
+		require.Nil(t, decoded.UnmarshalBinary(data))
+
+		localized, locale := decoded.Search("/catalog/shoes", new(Params)).LocaleData([]string{"en-gb"})
+		require.Equal(t, "catalogue", *localized.MetaTitle)
+		require.Equal(t, "en-gb", locale)
+
+		fn := trie.Freeze().Search("/catalog/shoes", new(Params))
+		require.Equal(t, "catalog", *fn.Localized["en"].MetaTitle)
+	})
+}
@@ -9,6 +9,14 @@ import (
 // See `Trie` too.
 type Node struct {
 	parent *Node
+	// label is the edge from the parent to this node: a named parameter (:), a wildcard (*),
+	// the root slash or one or more static segments joined by slashes, e.g. "a/b/c",
+	// the chains of static segments without branches are compressed into a single edge.
+	// The children are keyed by the first segment of their labels.
+	label string
+
+	// constraint restricts the values of the named parameter node, if any, see `Trie.Insert`.
+	constraint *constraint
 
 	children               map[string]*Node
 	hasDynamicChild        bool // does one of the children contains a parameter or wildcard?
@@ -22,8 +30,16 @@ type Node struct {
 	// we need it to track the static part for the closest-wildcard's parameter storage.
 	staticKey string
 
+	// queries are the query variants of the path, see `Trie.Insert`,
+	// a variant is a final node (its parent is the path node) with the query pattern.
+	queries []*Node
+	query   *queryPattern
+
 	// other insert data.
 	Data *SeoData
+	// Localized is the data of the pattern per locale, see `Trie.InsertLocale`.
+	Localized map[string]*SeoData
+	Redirect  *Redirect
 }
 
 // NewNode returns a new, empty, Node.
@@ -32,11 +48,12 @@ func NewNode() *Node {
 	return n
 }
 
-func (n *Node) addChild(s string, child *Node) {
+func (n *Node) addChild(child *Node) {
 	if n.children == nil {
 		n.children = make(map[string]*Node)
 	}
 
+	s := edgeKey(child.label)
 	if _, exists := n.children[s]; exists {
 		return
 	}
@@ -45,6 +62,104 @@ func (n *Node) addChild(s string, child *Node) {
 	n.children[s] = child
 }
 
+// edgeKey returns the first segment of the edge "label", the key of the node in its parent's children.
+func edgeKey(label string) string {
+	if label == pathSep {
+		return label
+	}
+
+	if i := strings.IndexByte(label, pathSepRune); i != -1 {
+		return label[:i]
+	}
+
+	return label
+}
+
+// isStaticLabel reports whether the "label" is made of static segments.
+func isStaticLabel(label string) bool {
+	return label != "" && label != pathSep && label != ParamStart && label != WildcardParamStart
+}
+
+// split cuts the static label of the node after its "k"th segment,
+// the head of the label goes to a new node which is inserted between the node and its parent.
+func (n *Node) split(k int) *Node {
+	segments := strings.Split(n.label, pathSep)
+
+	head := NewNode()
+	head.label = strings.Join(segments[:k], pathSep)
+	head.parent = n.parent
+	n.parent.children[edgeKey(head.label)] = head // the same key, the first segment is kept.
+
+	n.label = strings.Join(segments[k:], pathSep)
+	head.addChild(n)
+
+	return head
+}
+
+// compact merges the node with its only child when both of them are static,
+// so a chain of static segments without branches stays a single edge.
+func (n *Node) compact() {
+	if n.parent == nil || n.end || n.queries != nil || len(n.children) != 1 || !isStaticLabel(n.label) {
+		return
+	}
+
+	var child *Node
+	for _, c := range n.children {
+		child = c
+	}
+
+	if !isStaticLabel(child.label) {
+		return
+	}
+
+	n.label += pathSep + child.label
+	n.children = child.children
+	for _, c := range n.children {
+		c.parent = n
+	}
+	n.queries = child.queries
+	for _, v := range n.queries {
+		v.parent = n
+	}
+
+	n.hasDynamicChild = child.hasDynamicChild
+	n.childNamedParameter = child.childNamedParameter
+	n.childWildcardParameter = child.childWildcardParameter
+	n.paramKeys = child.paramKeys
+	n.end = child.end
+	n.key = child.key
+	n.staticKey = child.staticKey
+	n.Data = child.Data
+	n.Localized = child.Localized
+	n.Redirect = child.Redirect
+}
+
+// removeChild removes the "child" and recomputes the dynamic child flags.
+func (n *Node) removeChild(child *Node) {
+	for s, c := range n.children {
+		if c == child {
+			delete(n.children, s)
+			break
+		}
+	}
+
+	child.parent = nil
+	n.childNamedParameter = n.hasChild(ParamStart)
+	n.childWildcardParameter = n.hasChild(WildcardParamStart)
+	n.hasDynamicChild = n.childNamedParameter || n.childWildcardParameter
+}
+
+// reset makes the node a non-final one.
+func (n *Node) reset() {
+	n.end = false
+	n.key = ""
+	n.staticKey = ""
+	n.paramKeys = nil
+	n.Data = nil
+	n.Localized = nil
+	n.Redirect = nil
+}
+
 func (n *Node) getChild(s string) *Node {
 	if n.children == nil {
 		return nil
@@ -77,7 +192,7 @@ type NodeKeysSorter = func(list []string) func(i, j int) bool
 // DefaultKeysSorter sorts as: first the "key (the path)" with the lowest number of slashes.
 var DefaultKeysSorter = func(list []string) func(i, j int) bool {
 	return func(i, j int) bool {
-		return len(strings.Split(list[i], pathSep)) < len(strings.Split(list[j], pathSep))
+		return strings.Count(list[i], pathSep) < strings.Count(list[j], pathSep)
 	}
 }
 
@@ -88,21 +203,29 @@ func (n *Node) Keys(sorter NodeKeysSorter) (list []string) {
 		return
 	}
 
+	list = n.appendKeys(list)
+
+	if sorter != nil {
+		sort.Slice(list, sorter(list))
+	}
+
+	return
+}
+
+func (n *Node) appendKeys(list []string) []string {
 	if n.end {
 		list = append(list, n.key)
 	}
 
-	if n.children != nil {
-		for _, child := range n.children {
-			list = append(list, child.Keys(sorter)...)
-		}
+	for _, v := range n.queries {
+		list = append(list, v.key)
 	}
 
-	if sorter != nil {
-		sort.Slice(list, sorter(list))
+	for _, child := range n.children {
+		list = child.appendKeys(list)
 	}
 
-	return
+	return list
 }
 
 // Parent returns the parent of that node, can return nil if this is the root node.
@@ -115,6 +238,16 @@ func (n *Node) String() string {
 	return n.key
 }
 
+// IsRootWildcard reports whether the node, or the path of a query variant, is the root wildcard,
+// the catch-all pattern the searches fall back to when none of the other patterns matches.
+func (n *Node) IsRootWildcard() bool {
+	if n.query != nil {
+		n = n.parent
+	}
+
+	return n.label == WildcardParamStart && n.parent != nil && n.parent.parent == nil
+}
+
 // IsEnd returns true if this Node is a final path, has a key.
 func (n *Node) IsEnd() bool {
 	return n.end
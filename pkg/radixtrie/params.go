@@ -0,0 +1,102 @@
+package radixtrie
+
+import (
+	"iter"
+	"sync"
+)
+
+// Param is a path parameter's key and value found by `Search`.
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params is a slice-backed `ParamsSetter` and `ParamsGetter`.
+// The parameters are kept in the order `Search` set them and
+// the underline slice is reused after `Reset`, so a pooled Params (see `AcquireParams`)
+// doesn't allocate on lookups.
+//
+// The zero value is ready to use.
+type Params struct {
+	list []Param
+}
+
+var paramsPool = sync.Pool{
+	New: func() any {
+		return &Params{list: make([]Param, 0, 8)}
+	},
+}
+
+// AcquireParams returns an empty Params from the pool,
+// call `ReleaseParams` when it's no longer used.
+func AcquireParams() *Params {
+	return paramsPool.Get().(*Params)
+}
+
+// ReleaseParams resets and puts the "p" back to the pool,
+// "p" and the values it returned from `Get` must not be used after that.
+func ReleaseParams(p *Params) {
+	p.Reset()
+	paramsPool.Put(p)
+}
+
+// Set stores the "value" of the "key" parameter, an existing value is replaced.
+func (p *Params) Set(key, value string) {
+	for i := range p.list {
+		if p.list[i].Key == key {
+			p.list[i].Value = value
+			return
+		}
+	}
+
+	p.list = append(p.list, Param{Key: key, Value: value})
+}
+
+// Get returns the value of the "key" parameter or an empty string.
+func (p *Params) Get(key string) string {
+	v, _ := p.Lookup(key)
+	return v
+}
+
+// Lookup returns the value of the "key" parameter and reports whether it was set.
+func (p *Params) Lookup(key string) (string, bool) {
+	for i := range p.list {
+		if p.list[i].Key == key {
+			return p.list[i].Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Len returns the number of the stored parameters.
+func (p *Params) Len() int {
+	return len(p.list)
+}
+
+// All iterates over the parameters in insertion order.
+func (p *Params) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, param := range p.list {
+			if !yield(param.Key, param.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Map returns a copy of the parameters as a map.
+func (p *Params) Map() map[string]string {
+	m := make(map[string]string, len(p.list))
+	for _, param := range p.list {
+		m[param.Key] = param.Value
+	}
+
+	return m
+}
+
+// Reset removes all parameters keeping the allocated memory.
+func (p *Params) Reset() {
+	clear(p.list)
+	p.list = p.list[:0]
+}
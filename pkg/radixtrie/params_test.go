@@ -0,0 +1,95 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_Params(t *testing.T) {
+	t.Run("should set, get and iterate in insertion order", func(t *testing.T) {
+		p := new(Params)
+		p.Set("category", "shoes")
+		p.Set("rest", "red/42")
+		p.Set("category", "boots")
+
+		require.Equal(t, 2, p.Len())
+		require.Equal(t, "boots", p.Get("category"))
+		require.Equal(t, "", p.Get("unknown"))
+
+		_, ok := p.Lookup("unknown")
+		require.False(t, ok)
+
+		keys := make([]string, 0)
+		for key := range p.All() {
+			keys = append(keys, key)
+		}
+		require.Equal(t, []string{"category", "rest"}, keys)
+		require.Equal(t, map[string]string{"category": "boots", "rest": "red/42"}, p.Map())
+
+		p.Reset()
+		require.Equal(t, 0, p.Len())
+		require.Equal(t, "", p.Get("category"))
+	})
+
+	t.Run("should return reset params from the pool", func(t *testing.T) {
+		p := AcquireParams()
+		p.Set("id", "1")
+		ReleaseParams(p)
+
+		p = AcquireParams()
+		defer ReleaseParams(p)
+		require.Equal(t, 0, p.Len())
+	})
+}
+
+func newBenchTrie() *Trie {
+	trie := NewTrie()
+	trie.Insert("/")
+	trie.Insert("/about/company/contacts")
+	trie.Insert("/catalog/:category")
+	trie.Insert("/catalog/:category/:brand/:model")
+	trie.Insert("/catalog/:category/*rest")
+	trie.Insert("/blog/*path")
+	trie.Insert("/*any")
+	return trie
+}
+
+var benchQueries = map[string]string{
+	"static":          "/about/company/contacts",
+	"named":           "/catalog/shoes/nike/air-max",
+	"wildcard":        "/catalog/shoes/red/42/sale",
+	"closestWildcard": "/blog/2025/03/some-post",
+	"rootWildcard":    "/unknown/page",
+}
+
+func Test_SearchAllocs(t *testing.T) {
+	trie := newBenchTrie()
+
+	for name, q := range benchQueries {
+		t.Run("should not allocate on "+name+" lookup", func(t *testing.T) {
+			allocs := testing.AllocsPerRun(100, func() {
+				p := AcquireParams()
+				if trie.Search(q, p) == nil {
+					t.Fatalf("%s is not found", q)
+				}
+				ReleaseParams(p)
+			})
+			require.Zero(t, allocs)
+		})
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	trie := newBenchTrie()
+
+	for name, q := range benchQueries {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				p := AcquireParams()
+				trie.Search(q, p)
+				ReleaseParams(p)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package radixtrie
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	// QueryStart is the character, as a string, which separates the path of a pattern (or url) from its query.
+	QueryStart = "?"
+
+	querySep        = "&"
+	queryValueSep   = "="
+	queryOptional   = "?"
+	fragmentStart   = '#'
+	maxQueryKeys    = 16
+	queryKeyInvalid = "?&=:*/# "
+)
+
+// DefaultIgnoredQueryKeys are the query parameters which are ignored by the lookups by default,
+// unless a pattern declares them, see `IgnoreQueryKeys`.
+var DefaultIgnoredQueryKeys = []string{"utm_*", "gclid", "yclid", "fbclid", "_openstat", "from"}
+
+// QueryIgnoreFunc reports whether the query parameter "key" of a url is ignored
+// when the pattern's query doesn't declare it.
+type QueryIgnoreFunc func(key string) bool
+
+// IgnoreQueryKeys returns a `QueryIgnoreFunc` which ignores the "keys",
+// a key which ends with * ignores all the keys with that prefix, e.g. utm_*.
+func IgnoreQueryKeys(keys ...string) QueryIgnoreFunc {
+	keys = append([]string(nil), keys...)
+	return func(key string) bool {
+		return matchQueryKeys(keys, key)
+	}
+}
+
+func matchQueryKeys(keys []string, key string) bool {
+	for _, k := range keys {
+		if prefix, ok := strings.CutSuffix(k, WildcardParamStart); ok {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		} else if k == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func defaultQueryIgnore(key string) bool {
+	return matchQueryKeys(DefaultIgnoredQueryKeys, key)
+}
+
+// queryKey is a declared query parameter of a pattern:
+// "name" is a required one, its value is captured as the "name" parameter,
+// "name=value" is a required one with the exact value and
+// "name?" is an optional one, its value is captured if present.
+type queryKey struct {
+	name     string
+	value    string
+	exact    bool
+	optional bool
+}
+
+// queryPattern is the query part of a pattern, e.g. /catalog/:category?color&size=42&sort?,
+// a url matches it when it has all the required keys and no other keys but the optional and ignored ones.
+type queryPattern struct {
+	keys      []queryKey // sorted by name.
+	captures  []string   // the names of the captured keys, in the keys order.
+	canonical string     // the keys in the canonical order, the patterns with the equal ones conflict.
+
+	exactCount    int
+	requiredCount int
+}
+
+func parseQueryPattern(raw string) (*queryPattern, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("empty query")
+	}
+
+	parts := strings.Split(raw, querySep)
+	if len(parts) > maxQueryKeys {
+		return nil, fmt.Errorf("query declares more than %d keys", maxQueryKeys)
+	}
+
+	p := &queryPattern{keys: make([]queryKey, 0, len(parts))}
+	for _, part := range parts {
+		var k queryKey
+		if name, ok := strings.CutSuffix(part, queryOptional); ok {
+			k.name, k.optional = name, true
+		} else if name, value, ok := strings.Cut(part, queryValueSep); ok {
+			if value == "" {
+				return nil, fmt.Errorf("query key %q: empty value", name)
+			}
+			k.name, k.value, k.exact = name, value, true
+		} else {
+			k.name = part
+		}
+
+		if k.name == "" {
+			return nil, fmt.Errorf("query %q contains an empty key", raw)
+		}
+
+		if strings.ContainsAny(k.name, queryKeyInvalid) || strings.ContainsAny(k.value, queryKeyInvalid[:2]) {
+			return nil, fmt.Errorf("query key %q: invalid characters", part)
+		}
+
+		for _, existing := range p.keys {
+			if existing.name == k.name {
+				return nil, fmt.Errorf("query key %q is declared twice", k.name)
+			}
+		}
+
+		p.keys = append(p.keys, k)
+	}
+
+	sort.Slice(p.keys, func(i, j int) bool { return p.keys[i].name < p.keys[j].name })
+
+	canonical := make([]string, len(p.keys))
+	for i, k := range p.keys {
+		switch {
+		case k.exact:
+			p.exactCount++
+			p.requiredCount++
+			canonical[i] = k.name + queryValueSep + k.value
+		case k.optional:
+			p.captures = append(p.captures, k.name)
+			canonical[i] = k.name + queryOptional
+		default:
+			p.requiredCount++
+			p.captures = append(p.captures, k.name)
+			canonical[i] = k.name
+		}
+	}
+	p.canonical = strings.Join(canonical, querySep)
+
+	return p, nil
+}
+
+// less reports whether the pattern is more specific than the "other" one,
+// the query variants of a path are tried in that order:
+// more exact keys first, then more required keys, then less optional keys.
+func (p *queryPattern) less(other *queryPattern) bool {
+	if p.exactCount != other.exactCount {
+		return p.exactCount > other.exactCount
+	}
+
+	if p.requiredCount != other.requiredCount {
+		return p.requiredCount > other.requiredCount
+	}
+
+	if len(p.keys) != len(other.keys) {
+		return len(p.keys) < len(other.keys)
+	}
+
+	return p.canonical < other.canonical
+}
+
+func (p *queryPattern) index(name string) int {
+	for i := range p.keys {
+		if p.keys[i].name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// match reports whether the url's "rawQuery" matches the pattern, the values of the keys are returned
+// in the keys order, only the first value of a repeated key is taken into account.
+func (p *queryPattern) match(rawQuery string, ignore QueryIgnoreFunc) (values [maxQueryKeys]string, seen uint32, ok bool) {
+	for rawQuery != "" {
+		var pair string
+		pair, rawQuery, _ = strings.Cut(rawQuery, querySep)
+		if pair == "" {
+			continue
+		}
+
+		key, value, _ := strings.Cut(pair, queryValueSep)
+		key = unescapeQuery(key)
+
+		i := p.index(key)
+		if i == -1 {
+			if ignore(key) {
+				continue
+			}
+			return values, 0, false
+		}
+
+		if seen&(1<<i) != 0 {
+			continue
+		}
+		seen |= 1 << i
+
+		values[i] = unescapeQuery(value)
+		if k := &p.keys[i]; k.exact && values[i] != k.value {
+			return values, 0, false
+		}
+	}
+
+	for i := range p.keys {
+		if !p.keys[i].optional && seen&(1<<i) == 0 {
+			return values, 0, false
+		}
+	}
+
+	return values, seen, true
+}
+
+// capture sets the values of the captured keys returned by `match`.
+func (p *queryPattern) capture(values *[maxQueryKeys]string, seen uint32, params ParamsSetter) {
+	for i := range p.keys {
+		if !p.keys[i].exact && seen&(1<<i) != 0 {
+			params.Set(p.keys[i].name, values[i])
+		}
+	}
+}
+
+// canonicalQuery returns the query of the matched url in the canonical form:
+// only the declared keys, sorted by name, the missing or empty optional keys are omitted.
+func (p *queryPattern) canonicalQuery(params ParamsGetter) string {
+	var b strings.Builder
+	for _, k := range p.keys {
+		value := k.value
+		if !k.exact {
+			value = params.Get(k.name)
+		}
+
+		if value == "" && k.optional {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString(querySep)
+		}
+		b.WriteString(url.QueryEscape(k.name))
+		b.WriteString(queryValueSep)
+		b.WriteString(url.QueryEscape(value))
+	}
+
+	return b.String()
+}
+
+func unescapeQuery(s string) string {
+	if !strings.ContainsAny(s, "%+") {
+		return s
+	}
+
+	if unescaped, err := url.QueryUnescape(s); err == nil {
+		return unescaped
+	}
+
+	return s
+}
+
+// splitURL returns the path and the query of the "url", the fragment is dropped.
+// A trailing slash of the path is dropped too, like `Insert` drops it of the patterns,
+// so "/catalog/shoes/" is searched as "/catalog/shoes".
+func splitURL(url string) (path, rawQuery string) {
+	path = url
+loop:
+	for i := 0; i < len(url); i++ {
+		switch url[i] {
+		case QueryStart[0]:
+			path, rawQuery = url[:i], url[i+1:]
+			if j := strings.IndexByte(rawQuery, fragmentStart); j != -1 {
+				rawQuery = rawQuery[:j]
+			}
+			break loop
+		case fragmentStart:
+			path = url[:i]
+			break loop
+		}
+	}
+
+	if len(path) > 1 && path[len(path)-1] == pathSepRune {
+		path = path[:len(path)-1]
+	}
+
+	return path, rawQuery
+}
+
+// addQuery adds the query variant of the path node, the variants are kept in the `queryPattern.less` order.
+func (n *Node) addQuery(v *Node) {
+	v.parent = n
+	n.queries = append(n.queries, v)
+	sort.SliceStable(n.queries, func(i, j int) bool { return n.queries[i].query.less(n.queries[j].query) })
+}
+
+// removeQuery removes the query variant "v" of the path node.
+func (n *Node) removeQuery(v *Node) {
+	for i, q := range n.queries {
+		if q == v {
+			n.queries = append(n.queries[:i], n.queries[i+1:]...)
+			break
+		}
+	}
+
+	if len(n.queries) == 0 {
+		n.queries = nil
+	}
+	v.parent = nil
+}
+
+func (n *Node) findQuery(canonical string) *Node {
+	for _, v := range n.queries {
+		if v.query.canonical == canonical {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// matchQuery returns the first query variant of the path node which matches the "rawQuery",
+// the path parameters values and the captured query values are set to the "params".
+func (n *Node) matchQuery(rawQuery string, ignore QueryIgnoreFunc, pathValues []string, params ParamsSetter, tr *tracer) *Node {
+	for _, v := range n.queries {
+		values, seen, ok := v.query.match(rawQuery, ignore)
+		if tr != nil {
+			tr.step(StepQuery, rawQuery, v, ok)
+		}
+
+		if !ok {
+			continue
+		}
+
+		for i, paramValue := range pathValues {
+			if len(v.paramKeys) > i {
+				params.Set(v.paramKeys[i], paramValue)
+			}
+		}
+		v.query.capture(&values, seen, params)
+
+		return v
+	}
+
+	return nil
+}
+
+// CanonicalQuery returns the query of the url matched by the node's pattern in the canonical form:
+// only the keys declared by the pattern, sorted by name, with the "params" values (see `Search`),
+// it is empty for the patterns without a query.
+func (n *Node) CanonicalQuery(params ParamsGetter) string {
+	if n == nil || n.query == nil {
+		return ""
+	}
+
+	return n.query.canonicalQuery(params)
+}
@@ -0,0 +1,161 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newQueryTrie(t *testing.T) *Trie {
+	trie := NewTrie()
+	for _, pattern := range []string{
+		"/catalog/:category",
+		"/catalog/:category?color",
+		"/catalog/:category?color&size=42",
+		"/catalog/:category?color&sort?",
+		"/brands?letter",
+		"/?page",
+	} {
+		require.Nil(t, trie.Insert(pattern))
+	}
+
+	return trie
+}
+
+func Test_QuerySearch(t *testing.T) {
+	trie := newQueryTrie(t)
+	frozen := trie.Freeze()
+
+	tests := []struct {
+		url     string
+		pattern string
+		params  map[string]string
+	}{
+		{"/catalog/shoes", "/catalog/:category", map[string]string{"category": "shoes"}},
+		{"/catalog/shoes?color=red", "/catalog/:category?color", map[string]string{"category": "shoes", "color": "red"}},
+		{"/catalog/shoes?size=42&color=red", "/catalog/:category?color&size=42", map[string]string{"category": "shoes", "color": "red"}},
+		{"/catalog/shoes?sort=asc&color=red", "/catalog/:category?color&sort?", map[string]string{"category": "shoes", "color": "red", "sort": "asc"}},
+		{"/catalog/shoes?color=red&size=43", "/catalog/:category", map[string]string{"category": "shoes"}},
+		{"/catalog/shoes?color=red&utm_source=mail&gclid=1", "/catalog/:category?color", map[string]string{"category": "shoes", "color": "red"}},
+		{"/catalog/shoes?color=dark+red&color=blue", "/catalog/:category?color", map[string]string{"category": "shoes", "color": "dark red"}},
+		{"/catalog/shoes?color=%D1%81%D0%B8%D0%BD%D0%B8%D0%B9#top", "/catalog/:category?color", map[string]string{"category": "shoes", "color": "синий"}},
+		{"/catalog/shoes?&color=red&", "/catalog/:category?color", map[string]string{"category": "shoes", "color": "red"}},
+		{"/brands?letter=a", "/brands?letter", map[string]string{"letter": "a"}},
+		{"/brands", "", map[string]string{}},
+		{"/brands?page=2", "", map[string]string{}},
+		{"/?page=2", "/?page", map[string]string{"page": "2"}},
+		{"/", "", map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		p := new(Params)
+		n := trie.Search(tt.url, p)
+		if tt.pattern == "" {
+			require.Nil(t, n, tt.url)
+		} else {
+			require.NotNil(t, n, tt.url)
+			require.Equal(t, tt.pattern, n.String(), tt.url)
+		}
+		require.Equal(t, tt.params, p.Map(), tt.url)
+
+		fp := new(Params)
+		fn := frozen.Search(tt.url, fp)
+		if tt.pattern == "" {
+			require.Nil(t, fn, tt.url)
+		} else {
+			require.Equal(t, tt.pattern, fn.String(), tt.url)
+		}
+		require.Equal(t, tt.params, fp.Map(), tt.url)
+	}
+}
+
+func Test_QueryInsert(t *testing.T) {
+	t.Run("should reject the variants with the same keys", func(t *testing.T) {
+		trie := newQueryTrie(t)
+
+		var conflict *ConflictError
+		require.ErrorAs(t, trie.Insert("/catalog/:slug?size=42&color"), &conflict)
+		require.Equal(t, "/catalog/:category?color&size=42", conflict.Existing)
+
+		require.Nil(t, trie.Insert("/catalog/:slug?size=43&color"))
+	})
+
+	t.Run("should reject invalid queries", func(t *testing.T) {
+		trie := NewTrie()
+		for _, pattern := range []string{"/a?", "/a?x&x", "/a?x=", "/a?&x", "/a?x:y"} {
+			require.NotNil(t, trie.Insert(pattern), pattern)
+			require.NotNil(t, ValidatePattern(pattern), pattern)
+		}
+
+		require.NotNil(t, ValidatePattern("/a/:color?color"))
+		require.Nil(t, ValidatePattern("/a/:category?color&size=42&sort?"))
+	})
+
+	t.Run("should return query param keys sorted by name after the path ones", func(t *testing.T) {
+		require.Equal(t, []string{"category", "color", "sort"}, ParamKeys("/catalog/:category?sort?&size=42&color"))
+	})
+
+	t.Run("should update and delete variants", func(t *testing.T) {
+		trie := newQueryTrie(t)
+
+		require.True(t, trie.Update("/brands?letter", WithData(&SeoData{MetaTitle: strPtr("brands")})))
+		require.Equal(t, "brands", *trie.Search("/brands?letter=b", new(Params)).Data.MetaTitle)
+
+		require.False(t, trie.Delete("/brands"))
+		require.True(t, trie.Delete("/brands?letter"))
+		require.Nil(t, trie.SearchPrefix("/brands"))
+
+		require.True(t, trie.Delete("/catalog/:category?color"))
+		require.Equal(t, "/catalog/:category?color&sort?", searchKey(trie, "/catalog/shoes?color=red"))
+
+		require.True(t, trie.Delete("/catalog/:category"))
+		require.Equal(t, "", searchKey(trie, "/catalog/shoes"))
+		require.ElementsMatch(t, []string{
+			"/catalog/:category?color&size=42",
+			"/catalog/:category?color&sort?",
+		}, trie.Autocomplete("/catalog", nil))
+	})
+
+	t.Run("should keep variants in binary snapshot", func(t *testing.T) {
+		trie := newQueryTrie(t)
+		data, err := trie.MarshalBinary()
+		require.Nil(t, err)
+
+		decoded := NewTrie()
+		require.Nil(t, decoded.UnmarshalBinary(data))
+		require.Equal(t, "/catalog/:category?color&size=42", searchKey(decoded, "/catalog/shoes?color=red&size=42"))
+		require.Equal(t, "/?page", searchKey(decoded, "/?page=1"))
+	})
+}
+
+func Test_QueryIgnore(t *testing.T) {
+	trie := newQueryTrie(t)
+	require.Equal(t, "/catalog/:category", searchKey(trie, "/catalog/shoes?color=red&ref=x"))
+
+	trie.SetQueryIgnore(IgnoreQueryKeys("ref", "utm_*"))
+	require.Equal(t, "/catalog/:category?color", searchKey(trie, "/catalog/shoes?color=red&ref=x&utm_medium=y"))
+	require.Equal(t, "/catalog/:category", searchKey(trie, "/catalog/shoes?color=red&gclid=1"))
+
+	trie.SetQueryIgnore(nil)
+	require.Equal(t, "/catalog/:category?color", searchKey(trie, "/catalog/shoes?color=red&gclid=1"))
+}
+
+func Test_CanonicalQuery(t *testing.T) {
+	trie := newQueryTrie(t)
+
+	p := new(Params)
+	n := trie.Search("/catalog/shoes?utm_source=x&size=42&color=dark+red", p)
+	require.Equal(t, "color=dark+red&size=42", n.CanonicalQuery(p))
+
+	p = new(Params)
+	n = trie.Search("/catalog/shoes?color=red", p)
+	require.Equal(t, "color=red", n.CanonicalQuery(p))
+
+	p = new(Params)
+	n = trie.Search("/catalog/shoes?sort=&color=red&sort=asc", p)
+	require.Equal(t, "/catalog/:category?color&sort?", n.String())
+	require.Equal(t, "color=red", n.CanonicalQuery(p))
+
+	p = new(Params)
+	n = trie.Search("/catalog/shoes", p)
+	require.Equal(t, "", n.CanonicalQuery(p))
+}
@@ -0,0 +1,127 @@
+package radixtrie
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Redirect tells that the urls matched by the node's pattern are moved to the `Target`,
+// the target can use the pattern's parameters as {{name}} placeholders, e.g.
+// /old/:category/*rest -> /catalog/{{category}}/{{rest}}.
+type Redirect struct {
+	Target string
+	Status int // the http status code, e.g. 301.
+
+	target *Template
+}
+
+// WithRedirect sets the node's optionally `Redirect` field.
+func WithRedirect(redirect *Redirect) InsertOption {
+	return func(n *Node) {
+		n.Redirect = redirect
+	}
+}
+
+// Compile precompiles the {{param}} placeholders of the target,
+// "paramKeys" are the parameters of the source pattern (see `ParamKeys`).
+// It should be called once, before the redirect is shared between goroutines.
+func (r *Redirect) Compile(paramKeys []string) error {
+	t, err := CompileTemplate(r.Target, paramKeys)
+	if err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+
+	r.target = nil
+	if !t.IsStatic() {
+		r.target = t
+	}
+
+	return nil
+}
+
+// Location returns the target with placeholders replaced by the "params" values.
+func (r *Redirect) Location(params ParamsGetter) string {
+	if r.target == nil {
+		return r.Target
+	}
+
+	return r.target.Render(params)
+}
+
+// sampleValues are the candidates for the parameter values of `SampleURL`.
+var sampleValues = []string{"sample", "1", "a", "x"}
+
+// SampleURL returns a url which is matched by the "pattern" if no more specific pattern exists:
+// the named parameters and wildcards are replaced with values satisfying their constraints,
+// the required query keys get their exact values or a sample value.
+// It reports false if no value satisfies a regular expression constraint.
+func SampleURL(pattern string) (string, bool) {
+	path, rawQuery, hasQuery := strings.Cut(pattern, QueryStart)
+	if path == "" || path == pathSep {
+		path = pathSep
+	} else {
+		segments := slowPathSplit(path)
+		for i, s := range segments {
+			if !isDynamicSegment(s) {
+				continue
+			}
+
+			_, spec, ok := splitConstraint(s[1:])
+			if !ok {
+				return "", false
+			}
+
+			value := sampleValues[0]
+			if spec != "" {
+				c, err := parseConstraint(spec)
+				if err != nil {
+					return "", false
+				}
+
+				if value, ok = c.sample(); !ok {
+					return "", false
+				}
+			}
+			segments[i] = value
+		}
+
+		path = pathSep + strings.Join(segments, pathSep)
+	}
+
+	if !hasQuery {
+		return path, true
+	}
+
+	query, err := parseQueryPattern(rawQuery)
+	if err != nil {
+		return "", false
+	}
+
+	values := url.Values{}
+	for _, k := range query.keys {
+		switch {
+		case k.exact:
+			values.Set(k.name, k.value)
+		case !k.optional:
+			values.Set(k.name, sampleValues[0])
+		}
+	}
+
+	return path + QueryStart + values.Encode(), true
+}
+
+// sample returns a value which satisfies the constraint.
+func (c *constraint) sample() (string, bool) {
+	if c.values != nil {
+		return c.values[0], true
+	}
+
+	for _, v := range sampleValues {
+		if c.match(v) {
+			return v, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,73 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_Redirect(t *testing.T) {
+	t.Run("should render target with params", func(t *testing.T) {
+		pattern := "/old/:category/*rest"
+		r := &Redirect{Target: "/catalog/{{category}}/{{rest}}", Status: 301}
+		require.Nil(t, r.Compile(ParamKeys(pattern)))
+
+		trie := NewTrie()
+		require.Nil(t, trie.Insert(pattern, WithRedirect(r)))
+
+		p := new(Params)
+		n := trie.Search("/old/shoes/red/42", p)
+		require.Equal(t, "/catalog/shoes/red/42", n.Redirect.Location(p))
+		require.Equal(t, 301, n.Redirect.Status)
+		require.Nil(t, n.Data)
+	})
+
+	t.Run("should reject unknown placeholders", func(t *testing.T) {
+		r := &Redirect{Target: "/catalog/{{slug}}", Status: 301}
+		require.NotNil(t, r.Compile(ParamKeys("/old/:category")))
+	})
+
+	t.Run("should keep redirects in binary snapshot and frozen trie", func(t *testing.T) {
+		r := &Redirect{Target: "/new/{{id}}", Status: 308}
+		require.Nil(t, r.Compile(ParamKeys("/old/:id<int>")))
+
+		trie := NewTrie()
+		require.Nil(t, trie.Insert("/old/:id<int>", WithRedirect(r)))
+		require.Nil(t, trie.Insert("/old/:id<int>/reviews", WithData(&SeoData{MetaTitle: strPtr("reviews")})))
+
+		data, err := trie.MarshalBinary()
+		require.Nil(t, err)
+		decoded := NewTrie()
+		require.Nil(t, decoded.UnmarshalBinary(data))
+
+		p := new(Params)
+		n := decoded.Search("/old/42", p)
+		require.Equal(t, "/new/42", n.Redirect.Location(p))
+		require.Equal(t, 308, n.Redirect.Status)
+
+		p = new(Params)
+		fn := trie.Freeze().Search("/old/42", p)
+		require.Equal(t, "/new/42", fn.Redirect.Location(p))
+	})
+}
+
+func Test_SampleURL(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		ok      bool
+	}{
+		{"/", "/", true},
+		{"/old/page", "/old/page", true},
+		{"/old/:slug/*rest", "/old/sample/sample", true},
+		{"/old/:id<int>/:lang<enum:ru,en>", "/old/1/ru", true},
+		{"/old/:code<[a-z]>", "/old/a", true},
+		{"/old/:code<[0-9]{3}>", "", false},
+		{"/old/:category?color&size=42&sort?", "/old/sample?color=sample&size=42", true},
+	}
+
+	for _, tt := range tests {
+		url, ok := SampleURL(tt.pattern)
+		require.Equal(t, tt.ok, ok, tt.pattern)
+		require.Equal(t, tt.url, url, tt.pattern)
+	}
+}
@@ -1,10 +1,147 @@
 package radixtrie
 
+import (
+	"fmt"
+)
+
+// FaqItem is a question with its answer of the page's FAQ block.
+type FaqItem struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	Order    int    `json:"order"`
+}
+
+// TagsCloudItem is a link of the page's tags cloud,
+// the weight is used by the frontends to scale the link.
+type TagsCloudItem struct {
+	Label  string  `json:"label"`
+	Href   string  `json:"href"`
+	Weight float64 `json:"weight"`
+}
+
+// HreflangAlternate is a link to the same page in another language or region.
+type HreflangAlternate struct {
+	Lang string `json:"lang"`
+	Href string `json:"href"`
+}
+
+// SeoData is the data of a `seo_declarations` row,
+// the "db" tags are the table's column names.
 type SeoData struct {
-	MetaRobots      *string
-	MetaTitle       *string
-	MetaDescription *string
-	MetaHeader      *string
-	MetaKeywords    *string
-	CanonicalLink   *string
+	MetaRobots      *string             `db:"meta_robots"`
+	MetaTitle       *string             `db:"meta_title"`
+	MetaDescription *string             `db:"meta_description"`
+	MetaHeader      *string             `db:"meta_header"` // the page's h1.
+	MetaKeywords    *string             `db:"meta_keywords"`
+	CanonicalLink   *string             `db:"canonical_link"`
+	OgTitle         *string             `db:"og_title"`
+	OgDescription   *string             `db:"og_description"`
+	OgImage         *string             `db:"og_image"`
+	OgType          *string             `db:"og_type"`
+	Faq             []FaqItem           `db:"faq"` // sorted by `FaqItem.Order`.
+	TagsCloud       []TagsCloudItem     `db:"tags_cloud"`
+	Hreflang        []HreflangAlternate `db:"hreflang"`
+
+	// templates are the compiled string fields, in the `stringFields` order,
+	// nil for the fields without placeholders, see `Compile`.
+	templates []*Template
+	// hreflangTemplates are the compiled `Hreflang` hrefs, same rules as `templates`.
+	hreflangTemplates []*Template
+}
+
+type seoDataField struct {
+	name  string
+	value **string
+}
+
+func (d *SeoData) stringFields() []seoDataField {
+	return []seoDataField{
+		{"meta_robots", &d.MetaRobots},
+		{"meta_title", &d.MetaTitle},
+		{"meta_description", &d.MetaDescription},
+		{"meta_header", &d.MetaHeader},
+		{"meta_keywords", &d.MetaKeywords},
+		{"canonical_link", &d.CanonicalLink},
+		{"og_title", &d.OgTitle},
+		{"og_description", &d.OgDescription},
+		{"og_image", &d.OgImage},
+		{"og_type", &d.OgType},
+	}
+}
+
+// Compile precompiles the {{param}} placeholders of the string fields and hreflang hrefs,
+// "paramKeys" are the parameters of the pattern the data is inserted with (see `ParamKeys`).
+// It should be called once, before the data is shared between goroutines.
+func (d *SeoData) Compile(paramKeys []string) error {
+	d.templates = nil
+
+	fields := d.stringFields()
+	for i, f := range fields {
+		if *f.value == nil {
+			continue
+		}
+
+		t, err := CompileTemplate(**f.value, paramKeys)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+
+		if t.IsStatic() {
+			continue
+		}
+
+		if d.templates == nil {
+			d.templates = make([]*Template, len(fields))
+		}
+		d.templates[i] = t
+	}
+
+	d.hreflangTemplates = nil
+	for i, alternate := range d.Hreflang {
+		t, err := CompileTemplate(alternate.Href, paramKeys)
+		if err != nil {
+			return fmt.Errorf("hreflang %s: %w", alternate.Lang, err)
+		}
+
+		if t.IsStatic() {
+			continue
+		}
+
+		if d.hreflangTemplates == nil {
+			d.hreflangTemplates = make([]*Template, len(d.Hreflang))
+		}
+		d.hreflangTemplates[i] = t
+	}
+
+	return nil
+}
+
+// Render returns the data with placeholders replaced by the "params" values.
+// If the data has no compiled placeholders then the data itself is returned.
+func (d *SeoData) Render(params ParamsGetter) *SeoData {
+	if d == nil || (d.templates == nil && d.hreflangTemplates == nil) {
+		return d
+	}
+
+	rendered := *d
+	if d.templates != nil {
+		for i, f := range rendered.stringFields() {
+			if t := d.templates[i]; t != nil {
+				s := t.Render(params)
+				*f.value = &s
+			}
+		}
+	}
+
+	if d.hreflangTemplates != nil {
+		rendered.Hreflang = make([]HreflangAlternate, len(d.Hreflang))
+		for i, alternate := range d.Hreflang {
+			if t := d.hreflangTemplates[i]; t != nil {
+				alternate.Href = t.Render(params)
+			}
+			rendered.Hreflang[i] = alternate
+		}
+	}
+
+	return &rendered
 }
@@ -0,0 +1,117 @@
+package radixtrie
+
+import (
+	"unsafe"
+)
+
+// Stats is the size and the shape of a trie, see `Trie.Stats`.
+type Stats struct {
+	// Nodes is the number of the nodes, including the root and the query variants.
+	Nodes int `json:"nodes"`
+	// EndNodes is the number of the final nodes, the inserted patterns.
+	EndNodes int `json:"endNodes"`
+	// QueryVariants is the number of the query variants of the path nodes.
+	QueryVariants int `json:"queryVariants"`
+	// NamedNodes and WildcardNodes are the number of the named parameter and wildcard nodes.
+	NamedNodes    int `json:"namedNodes"`
+	WildcardNodes int `json:"wildcardNodes"`
+	// MaxDepth is the number of the edges from the root to the deepest node,
+	// a compressed chain of static segments is a single edge.
+	MaxDepth int `json:"maxDepth"`
+	// FanOut is the histogram of the number of the children: the number of the nodes per children count.
+	FanOut map[int]int `json:"fanOut"`
+	// EstimatedBytes is the rough heap size of the nodes with their data and redirects,
+	// without the compiled templates and the maps' overhead beyond their entries.
+	EstimatedBytes int64 `json:"estimatedBytes"`
+}
+
+const (
+	nodeBytes     = int64(unsafe.Sizeof(Node{}))
+	stringBytes   = int64(unsafe.Sizeof(""))
+	pointerBytes  = int64(unsafe.Sizeof(uintptr(0)))
+	mapEntryBytes = stringBytes + pointerBytes + 8 // key, value and the bucket's hash and overflow share.
+)
+
+// Stats walks the trie and returns its `Stats`.
+// It visits every node, so it should be called once per load rather than per request.
+// The searches don't count anything, see `Node.IsRootWildcard` to count the root wildcard fallbacks.
+func (t *Trie) Stats() Stats {
+	s := Stats{FanOut: make(map[int]int)}
+	s.walk(t.root, 0)
+
+	return s
+}
+
+func (s *Stats) walk(n *Node, depth int) {
+	s.add(n)
+	s.FanOut[len(n.children)]++
+	s.MaxDepth = max(s.MaxDepth, depth)
+
+	switch n.label {
+	case ParamStart:
+		s.NamedNodes++
+	case WildcardParamStart:
+		s.WildcardNodes++
+	}
+
+	for _, v := range n.queries {
+		s.QueryVariants++
+		s.add(v)
+		s.EstimatedBytes += pointerBytes + int64(unsafe.Sizeof(queryPattern{})) + int64(len(v.query.canonical)) +
+			int64(len(v.query.keys))*int64(unsafe.Sizeof(queryKey{})) + int64(len(v.query.captures))*stringBytes
+	}
+
+	for _, child := range n.children {
+		s.walk(child, depth+1)
+	}
+}
+
+// add counts the node and its own size.
+func (s *Stats) add(n *Node) {
+	s.Nodes++
+	if n.end {
+		s.EndNodes++
+	}
+
+	s.EstimatedBytes += nodeBytes + int64(len(n.label)+len(n.key)+len(n.staticKey)) +
+		int64(len(n.children))*mapEntryBytes + int64(len(n.paramKeys))*stringBytes
+	for _, k := range n.paramKeys {
+		s.EstimatedBytes += int64(len(k))
+	}
+
+	s.EstimatedBytes += n.Data.estimatedBytes()
+	for locale, data := range n.Localized {
+		s.EstimatedBytes += mapEntryBytes + int64(len(locale)) + data.estimatedBytes()
+	}
+
+	if n.Redirect != nil {
+		s.EstimatedBytes += int64(unsafe.Sizeof(Redirect{})) + int64(len(n.Redirect.Target))
+	}
+}
+
+func (d *SeoData) estimatedBytes() int64 {
+	if d == nil {
+		return 0
+	}
+
+	size := int64(unsafe.Sizeof(SeoData{}))
+	for _, f := range d.stringFields() {
+		if *f.value != nil {
+			size += stringBytes + int64(len(**f.value))
+		}
+	}
+
+	for _, item := range d.Faq {
+		size += int64(unsafe.Sizeof(item)) + int64(len(item.Question)+len(item.Answer))
+	}
+
+	for _, item := range d.TagsCloud {
+		size += int64(unsafe.Sizeof(item)) + int64(len(item.Label)+len(item.Href))
+	}
+
+	for _, item := range d.Hreflang {
+		size += int64(unsafe.Sizeof(item)) + int64(len(item.Lang)+len(item.Href))
+	}
+
+	return size
+}
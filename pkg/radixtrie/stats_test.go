@@ -0,0 +1,43 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_Stats(t *testing.T) {
+	trie := NewTrie()
+	for _, p := range []string{
+		"/",
+		"/*any",
+		"/catalog/:category",
+		"/catalog/:category?color",
+		"/catalog/sale/shoes",
+		"/catalog/:category/*rest",
+	} {
+		require.Nil(t, trie.Insert(p, WithData(&SeoData{MetaTitle: strPtr(p)})))
+	}
+
+	s := trie.Stats()
+	// root, "/", "*", "catalog", ":", "sale/shoes", "*" and the "?color" variant.
+	require.Equal(t, 8, s.Nodes)
+	require.Equal(t, 6, s.EndNodes)
+	require.Equal(t, 1, s.QueryVariants)
+	require.Equal(t, 1, s.NamedNodes)
+	require.Equal(t, 2, s.WildcardNodes)
+	require.Equal(t, 3, s.MaxDepth)
+	require.Equal(t, map[int]int{0: 4, 1: 1, 2: 1, 3: 1}, s.FanOut)
+	require.Positive(t, s.EstimatedBytes)
+
+	t.Run("should estimate data size", func(t *testing.T) {
+		require.Nil(t, trie.InsertLocale("/catalog/sale/shoes", "en", &SeoData{MetaTitle: strPtr("sale")}))
+		require.Greater(t, trie.Stats().EstimatedBytes, s.EstimatedBytes)
+	})
+
+	t.Run("should report root wildcard", func(t *testing.T) {
+		require.False(t, trie.Search("/catalog/shoes", new(Params)).IsRootWildcard())
+		require.True(t, trie.Search("/about", new(Params)).IsRootWildcard())
+		require.True(t, trie.Search("/catalog", new(Params)).IsRootWildcard())
+		require.False(t, trie.Search("/catalog/shoes/red", new(Params)).IsRootWildcard())
+	})
+}
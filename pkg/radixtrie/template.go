@@ -0,0 +1,106 @@
+package radixtrie
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	templateOpen  = "{{"
+	templateClose = "}}"
+)
+
+// ParamsGetter is the interface which should be implemented by the
+// params reader for `Template.Render` and `SeoData.Render`,
+// it's usually the same value that was passed to `Search` as `ParamsSetter`.
+type ParamsGetter interface {
+	Get(string) string
+}
+
+type templatePart struct {
+	text  string
+	param bool // if true then text is the param key.
+}
+
+// Template is a precompiled string with {{param}} placeholders,
+// see `CompileTemplate`.
+type Template struct {
+	parts []templatePart
+}
+
+// CompileTemplate parses "s" and checks that each placeholder
+// refers to one of the "paramKeys" of the pattern the template belongs to.
+func CompileTemplate(s string, paramKeys []string) (*Template, error) {
+	t := new(Template)
+
+	for s != "" {
+		open := strings.Index(s, templateOpen)
+		if open == -1 {
+			t.parts = append(t.parts, templatePart{text: s})
+			break
+		}
+
+		if open > 0 {
+			t.parts = append(t.parts, templatePart{text: s[:open]})
+		}
+
+		s = s[open+len(templateOpen):]
+		end := strings.Index(s, templateClose)
+		if end == -1 {
+			return nil, fmt.Errorf("unclosed placeholder %q", templateOpen+s)
+		}
+
+		key := strings.TrimSpace(s[:end])
+		if key == "" {
+			return nil, fmt.Errorf("empty placeholder")
+		}
+
+		if !containsString(paramKeys, key) {
+			return nil, fmt.Errorf("unknown parameter %q in placeholder", key)
+		}
+
+		t.parts = append(t.parts, templatePart{text: key, param: true})
+		s = s[end+len(templateClose):]
+	}
+
+	return t, nil
+}
+
+// IsStatic reports whether the template has no placeholders.
+func (t *Template) IsStatic() bool {
+	for _, p := range t.parts {
+		if p.param {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Render returns the template's string with placeholders replaced by the "params" values.
+func (t *Template) Render(params ParamsGetter) string {
+	if len(t.parts) == 1 && !t.parts[0].param {
+		return t.parts[0].text
+	}
+
+	var b strings.Builder
+	for _, p := range t.parts {
+		if p.param {
+			b.WriteString(params.Get(p.text))
+			continue
+		}
+		b.WriteString(p.text)
+	}
+
+	return b.String()
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
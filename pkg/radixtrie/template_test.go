@@ -0,0 +1,112 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testParams map[string]string
+
+func (p testParams) Set(key, value string) {
+	p[key] = value
+}
+
+func (p testParams) Get(key string) string {
+	return p[key]
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func Test_CompileTemplate(t *testing.T) {
+	keys := []string{"category", "rest"}
+
+	t.Run("should render placeholders", func(t *testing.T) {
+		tpl, err := CompileTemplate("Buy {{category}} ({{ rest }}) online", keys)
+		require.Nil(t, err)
+		require.False(t, tpl.IsStatic())
+		require.Equal(t, "Buy shoes (red/42) online", tpl.Render(testParams{"category": "shoes", "rest": "red/42"}))
+	})
+
+	t.Run("should keep static strings as is", func(t *testing.T) {
+		tpl, err := CompileTemplate("Static title", keys)
+		require.Nil(t, err)
+		require.True(t, tpl.IsStatic())
+		require.Equal(t, "Static title", tpl.Render(testParams{}))
+	})
+
+	t.Run("should reject invalid templates", func(t *testing.T) {
+		_, err := CompileTemplate("Buy {{category", keys)
+		require.ErrorContains(t, err, "unclosed placeholder")
+
+		_, err = CompileTemplate("Buy {{ }}", keys)
+		require.ErrorContains(t, err, "empty placeholder")
+
+		_, err = CompileTemplate("Buy {{color}}", keys)
+		require.ErrorContains(t, err, `unknown parameter "color"`)
+	})
+}
+
+func Test_SeoDataRender(t *testing.T) {
+	pattern := "/catalog/:category/*rest"
+
+	t.Run("should render compiled fields from search params", func(t *testing.T) {
+		data := &SeoData{
+			MetaTitle:       strPtr("{{category}} catalog"),
+			MetaDescription: strPtr("static"),
+			CanonicalLink:   strPtr("/catalog/{{category}}"),
+		}
+		require.Nil(t, data.Compile(ParamKeys(pattern)))
+
+		trie := NewTrie()
+		trie.Insert(pattern, WithData(data))
+
+		params := testParams{}
+		n := trie.Search("/catalog/shoes/red", params)
+		require.NotNil(t, n)
+
+		rendered := n.Data.Render(params)
+		require.Equal(t, "shoes catalog", *rendered.MetaTitle)
+		require.Equal(t, "static", *rendered.MetaDescription)
+		require.Equal(t, "/catalog/shoes", *rendered.CanonicalLink)
+		require.Nil(t, rendered.MetaRobots)
+		require.Equal(t, "{{category}} catalog", *data.MetaTitle, "source data must not be modified")
+	})
+
+	t.Run("should return the same data when there is nothing to render", func(t *testing.T) {
+		data := &SeoData{MetaTitle: strPtr("static")}
+		require.Nil(t, data.Compile(ParamKeys(pattern)))
+		require.Same(t, data, data.Render(testParams{}))
+
+		var empty *SeoData
+		require.Nil(t, empty.Render(testParams{}))
+	})
+
+	t.Run("should report field of invalid template", func(t *testing.T) {
+		data := &SeoData{MetaKeywords: strPtr("{{unknown}}")}
+		require.ErrorContains(t, data.Compile(ParamKeys(pattern)), "meta_keywords")
+	})
+}
+
+func Test_SeoDataRenderHreflang(t *testing.T) {
+	t.Run("should render hreflang hrefs without modifying source data", func(t *testing.T) {
+		data := &SeoData{Hreflang: []HreflangAlternate{
+			{Lang: "en", Href: "/en/catalog/{{category}}"},
+			{Lang: "x-default", Href: "/catalog"},
+		}}
+		require.Nil(t, data.Compile([]string{"category"}))
+
+		rendered := data.Render(testParams{"category": "shoes"})
+		require.Equal(t, []HreflangAlternate{
+			{Lang: "en", Href: "/en/catalog/shoes"},
+			{Lang: "x-default", Href: "/catalog"},
+		}, rendered.Hreflang)
+		require.Equal(t, "/en/catalog/{{category}}", data.Hreflang[0].Href)
+	})
+
+	t.Run("should report lang of invalid hreflang template", func(t *testing.T) {
+		data := &SeoData{Hreflang: []HreflangAlternate{{Lang: "en", Href: "/en/{{unknown}}"}}}
+		require.ErrorContains(t, data.Compile([]string{"category"}), "hreflang en")
+	})
+}
@@ -1,7 +1,10 @@
 package radixtrie
 
 import (
+	"fmt"
+	"sort"
 	"strings"
+	"sync"
 )
 
 const (
@@ -22,6 +25,20 @@ type Trie struct {
 	hasRootWildcard bool
 
 	hasRootSlash bool
+
+	// ignoreQuery reports the query parameters the lookups skip, `defaultQueryIgnore` if nil.
+	ignoreQuery QueryIgnoreFunc
+
+	// backtracking enables the full backtracking `Search`, see `SetBacktracking`.
+	backtracking bool
+
+	// foldCase matches the static segments case-insensitively, see `SetCaseFolding`.
+	foldCase bool
+
+	// keys is the lexically sorted index of the inserted keys, built lazily by `List`
+	// and `Autocomplete` and dropped on `Insert` and `Delete`.
+	keysMu sync.Mutex
+	keys   []string
 }
 
 // NewTrie returns a new, empty Trie.
@@ -35,6 +52,41 @@ func NewTrie() *Trie {
 	}
 }
 
+// SetQueryIgnore sets the rule of the query parameters which are ignored by `Search`
+// when the pattern doesn't declare them, nil restores the `DefaultIgnoredQueryKeys` one.
+// It must be called before the trie is shared between goroutines.
+func (t *Trie) SetQueryIgnore(ignore QueryIgnoreFunc) {
+	t.ignoreQuery = ignore
+}
+
+// SetBacktracking switches `Search` to the backtracking mode: when a branch does not lead
+// to a pattern, the search returns to the previous segments and tries their next alternatives
+// in the order static -> named parameter -> wildcard, so with /a/static/x and /a/:p/y
+// the /a/static/y url is matched by the /a/:p/y.
+// The first pattern found in this order wins, i.e. of all the matching patterns the one
+// which has a static segment, then a named parameter, where the others have not.
+// It must be called before the trie is shared between goroutines.
+func (t *Trie) SetBacktracking(enabled bool) {
+	t.backtracking = enabled
+}
+
+// SetCaseFolding makes `Search` match the static segments of the url case-insensitively,
+// while the named parameters and wildcards take the values of the url as they are,
+// so their constraints and the rendered data see the original case.
+// The static segments of the patterns must be lowercase then, e.g. inserted through a lowercasing normalizer.
+// It must be called before the trie is shared between goroutines.
+func (t *Trie) SetCaseFolding(enabled bool) {
+	t.foldCase = enabled
+}
+
+func (t *Trie) queryIgnore() QueryIgnoreFunc {
+	if t.ignoreQuery == nil {
+		return defaultQueryIgnore
+	}
+
+	return t.ignoreQuery
+}
+
 // InsertOption is just a function which accepts a pointer to a Node which can alt its `Handler`, `Tag` and `Data`  fields.
 //
 // See `WithHandler`, `WithTag` and `WithData`.
@@ -49,15 +101,52 @@ func WithData(data *SeoData) InsertOption {
 }
 
 // Insert adds a node to the trie.
-func (t *Trie) Insert(pattern string, options ...InsertOption) {
+// It returns `ErrEmptyPattern` for an empty pattern and a `*ConflictError` if the pattern
+// resolves to a node which is already taken by another (or the same) pattern,
+// e.g. /a/:id and /a/:slug, in that case the trie is left untouched.
+//
+// A named parameter can be constrained, e.g. :id<int>, :lang<enum:ru,en> or :slug<[a-z0-9-]+>,
+// then `Search` takes it only if the segment satisfies the constraint.
+// The parameters at the same position must have the same constraint, otherwise
+// the pattern conflicts with the already inserted ones.
+//
+// The pattern can declare a query after the path, e.g. /catalog/:category?color&size=42&sort?,
+// where "color" is a required key which value is captured as the "color" parameter,
+// "size=42" is a required key with the exact value and "sort?" is an optional key.
+// Such variants of a path are matched by the url's query, see `Search`,
+// the variants with the same keys (in any order) conflict.
+func (t *Trie) Insert(pattern string, options ...InsertOption) error {
 	if pattern == "" {
-		panic("Insert: empty pattern")
+		return ErrEmptyPattern
+	}
+
+	path, rawQuery, hasQuery := strings.Cut(pattern, QueryStart)
+	if err := t.checkConstraints(pattern, path); err != nil {
+		return err
 	}
 
-	n := t.insert(pattern, "", nil, nil)
+	if hasQuery {
+		query, err := parseQueryPattern(rawQuery)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+
+		if n := t.walk(path); n != nil {
+			if existing := n.findQuery(query.canonical); existing != nil {
+				return &ConflictError{Pattern: pattern, Existing: existing.key}
+			}
+		}
+	} else if existing := t.walk(pattern); existing != nil && existing.end {
+		return &ConflictError{Pattern: pattern, Existing: existing.key}
+	}
+
+	n := t.insert(pattern, "", nil)
+	t.dropKeys()
 	for _, opt := range options {
 		opt(n)
 	}
+
+	return nil
 }
 
 func slowPathSplit(path string) []string {
@@ -85,69 +174,373 @@ func resolveStaticPart(key string) string {
 	return key[:i]
 }
 
+// ParamKeys returns the named parameters and wildcard keys of the "pattern"
+// (without : or *), in the order they appear in the pattern,
+// followed by the captured keys of the pattern's query, sorted by name.
+func ParamKeys(pattern string) (keys []string) {
+	path, rawQuery, hasQuery := strings.Cut(pattern, QueryStart)
+
+	if path != "" && path != pathSep {
+		for _, s := range slowPathSplit(path) {
+			if s != "" && (s[0] == ParamStart[0] || s[0] == WildcardParamStart[0]) {
+				name, _, _ := splitConstraint(s[1:])
+				keys = append(keys, name)
+			}
+		}
+	}
+
+	if hasQuery {
+		if query, err := parseQueryPattern(rawQuery); err == nil {
+			keys = append(keys, query.captures...)
+		}
+	}
+
+	return
+}
+
 func (t *Trie) insert(key, tag string, optionalData *SeoData) *Node {
-	input := slowPathSplit(key)
+	path, rawQuery, hasQuery := strings.Cut(key, QueryStart)
+	input := slowPathSplit(path)
 
 	n := t.root
-	if key == pathSep {
+	if path == pathSep {
 		t.hasRootSlash = true
 	}
 
 	var paramKeys []string
 
-	for _, s := range input {
+	for i := 0; i < len(input); {
+		s := input[i]
 		c := s[0]
 
-		if isParam, isWildcard := c == ParamStart[0], c == WildcardParamStart[0]; isParam || isWildcard {
-			n.hasDynamicChild = true
-			paramKeys = append(paramKeys, s[1:]) // without : or *.
-
-			// if node has already a wildcard, don't force a value, check for true only.
-			if isParam {
-				n.childNamedParameter = true
-				s = ParamStart
+		isParam, isWildcard := c == ParamStart[0], c == WildcardParamStart[0]
+		if !isParam && !isWildcard {
+			// the static segments up to the next parameter are a single (compressed) edge.
+			j := i + 1
+			for j < len(input) && !isDynamicSegment(input[j]) {
+				j++
 			}
 
-			if isWildcard {
-				n.childWildcardParameter = true
-				s = WildcardParamStart
-				if t.root == n {
-					t.hasRootWildcard = true
-				}
+			n = n.insertStatic(input[i:j])
+			i = j
+			continue
+		}
+
+		n.hasDynamicChild = true
+		name, spec, _ := splitConstraint(s[1:])
+		paramKeys = append(paramKeys, name) // without : or *.
+
+		// if node has already a wildcard, don't force a value, check for true only.
+		if isParam {
+			n.childNamedParameter = true
+			s = ParamStart
+		}
+
+		if isWildcard {
+			n.childWildcardParameter = true
+			s = WildcardParamStart
+			if t.root == n {
+				t.hasRootWildcard = true
 			}
 		}
 
 		if !n.hasChild(s) {
 			child := NewNode()
-			n.addChild(s, child)
+			child.label = s
+			if spec != "" {
+				// the constraint is validated by `Insert`.
+				child.constraint, _ = parseConstraint(spec)
+			}
+			n.addChild(child)
 		}
 
 		n = n.getChild(s)
+		i++
+	}
+
+	if hasQuery {
+		// the query is validated by `Insert`.
+		query, _ := parseQueryPattern(rawQuery)
+
+		v := NewNode()
+		v.query = query
+		paramKeys = append(paramKeys, query.captures...)
+		n.addQuery(v)
+		n = v
 	}
 
 	n.Data = optionalData
 
 	n.paramKeys = paramKeys
 	n.key = key
-	n.staticKey = resolveStaticPart(key)
+	n.staticKey = resolveStaticPart(path)
 	n.end = true
 
 	return n
 }
 
+// checkConstraints returns an error if a constraint of the "path" is invalid
+// and a `*ConflictError` if a named parameter of the "path" is constrained differently
+// from the existing one at the same position.
+func (t *Trie) checkConstraints(pattern, path string) error {
+	input := slowPathSplit(path)
+	for _, s := range input {
+		if !isDynamicSegment(s) {
+			continue
+		}
+
+		_, spec, ok := splitConstraint(s[1:])
+		if !ok {
+			return fmt.Errorf("pattern %q: invalid constraint in segment %q", pattern, s)
+		}
+
+		if spec != "" {
+			if s[0] == WildcardParamStart[0] {
+				return fmt.Errorf("pattern %q: wildcard %q can't be constrained", pattern, s)
+			}
+
+			if _, err := parseConstraint(spec); err != nil {
+				return fmt.Errorf("pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	n := t.root
+	for len(input) > 0 && n != nil {
+		s := input[0]
+		if s == "" {
+			return nil
+		}
+
+		switch s[0] {
+		case ParamStart[0]:
+			if n = n.getChild(ParamStart); n == nil {
+				return nil
+			}
+
+			if _, spec, _ := splitConstraint(s[1:]); spec != n.constraint.String() {
+				keys := n.Keys(nil)
+				sort.Strings(keys)
+				return &ConflictError{Pattern: pattern, Existing: keys[0]}
+			}
+
+			input = input[1:]
+		case WildcardParamStart[0]:
+			return nil
+		default:
+			if n = n.getChild(s); n == nil {
+				return nil
+			}
+
+			k := matchLabel(n.label, input)
+			if k != labelSegments(n.label) {
+				return nil
+			}
+			input = input[k:]
+		}
+	}
+
+	return nil
+}
+
+func isDynamicSegment(s string) bool {
+	return s != "" && (s[0] == ParamStart[0] || s[0] == WildcardParamStart[0])
+}
+
+// insertStatic returns the node of the static "segments" below n,
+// the existing edges are split where the segments diverge from them.
+func (n *Node) insertStatic(segments []string) *Node {
+	for len(segments) > 0 {
+		child := n.getChild(segments[0])
+		if child == nil {
+			child = NewNode()
+			child.label = strings.Join(segments, pathSep)
+			n.addChild(child)
+			return child
+		}
+
+		k := matchLabel(child.label, segments)
+		if k < labelSegments(child.label) {
+			child = child.split(k)
+		}
+
+		n = child
+		segments = segments[k:]
+	}
+
+	return n
+}
+
+// labelSegments returns the number of segments of the edge "label".
+func labelSegments(label string) int {
+	if label == pathSep {
+		return 1
+	}
+
+	return strings.Count(label, pathSep) + 1
+}
+
+// matchLabel returns the number of the leading "segments" which are equal to the segments of the "label".
+func matchLabel(label string, segments []string) int {
+	if label == pathSep {
+		if len(segments) > 0 && segments[0] == pathSep {
+			return 1
+		}
+		return 0
+	}
+
+	k := 0
+	for k < len(segments) {
+		s := segments[k]
+		if !strings.HasPrefix(label, s) || (len(label) > len(s) && label[len(s)] != pathSepRune) {
+			break
+		}
+
+		k++
+		if len(label) == len(s) {
+			break
+		}
+		label = label[len(s)+1:]
+	}
+
+	return k
+}
+
+// find returns the final node which was inserted with exactly the "pattern" key, if any.
+func (t *Trie) find(pattern string) *Node {
+	path, _, hasQuery := strings.Cut(pattern, QueryStart)
+	n := t.walk(path)
+	if n == nil {
+		return nil
+	}
+
+	if hasQuery {
+		for _, v := range n.queries {
+			if v.key == pattern {
+				return v
+			}
+		}
+
+		return nil
+	}
+
+	if !n.end || n.key != pattern {
+		return nil
+	}
+
+	return n
+}
+
+// walk returns the node the "pattern" resolves to, if it exists,
+// the parameter names are not taken into account.
+func (t *Trie) walk(pattern string) *Node {
+	if pattern == "" {
+		return nil
+	}
+
+	n := t.root
+	input := slowPathSplit(pattern)
+	for len(input) > 0 {
+		s := input[0]
+		if s == "" {
+			return nil
+		}
+
+		switch s[0] {
+		case ParamStart[0]:
+			s = ParamStart
+		case WildcardParamStart[0]:
+			s = WildcardParamStart
+		}
+
+		if n = n.getChild(s); n == nil {
+			return nil
+		}
+
+		k := 1
+		if isStaticLabel(n.label) {
+			// the pattern must not end or diverge in the middle of a compressed edge.
+			if k = matchLabel(n.label, input); k != labelSegments(n.label) {
+				return nil
+			}
+		}
+		input = input[k:]
+	}
+
+	return n
+}
+
+// Update applies the "options" to the node inserted with the "pattern",
+// e.g. `WithData` to replace its data without rebuilding the trie.
+// It reports whether the pattern was found.
+//
+// Like `Insert` and `Delete`, it must not be called concurrently with the lookups.
+func (t *Trie) Update(pattern string, options ...InsertOption) bool {
+	n := t.find(pattern)
+	if n == nil {
+		return false
+	}
+
+	for _, opt := range options {
+		opt(n)
+	}
+
+	return true
+}
+
+// Delete removes the "pattern" inserted by `Insert` and reports whether it was found.
+// The nodes which are left without a key and children are pruned, the static edges which are
+// left without a branch are merged and the parents' dynamic child flags,
+// as well as the root wildcard and root slash ones, are recomputed.
+func (t *Trie) Delete(pattern string) bool {
+	n := t.find(pattern)
+	if n == nil {
+		return false
+	}
+
+	if n.query != nil {
+		path := n.parent
+		path.removeQuery(n)
+		n = path
+	} else {
+		n.reset()
+	}
+
+	for n != t.root && !n.end && n.queries == nil && len(n.children) == 0 {
+		parent := n.parent
+		parent.removeChild(n)
+		n = parent
+	}
+	n.compact()
+	t.dropKeys()
+
+	t.hasRootWildcard = t.root.hasChild(WildcardParamStart)
+	t.hasRootSlash = t.root.hasChild(pathSep)
+
+	return true
+}
+
 // SearchPrefix returns the last node which holds the key which starts with "prefix".
+// If the prefix ends in the middle of a compressed edge then the node below the edge is returned.
 func (t *Trie) SearchPrefix(prefix string) *Node {
 	input := slowPathSplit(prefix)
 	n := t.root
 
-	for i := 0; i < len(input); i++ {
-		s := input[i]
-		if child := n.getChild(s); child != nil {
-			n = child
-			continue
+	for len(input) > 0 {
+		child := n.getChild(input[0])
+		if child == nil {
+			return nil
 		}
 
-		return nil
+		k := 1
+		if isStaticLabel(child.label) {
+			if k = matchLabel(child.label, input); k < len(input) && k != labelSegments(child.label) {
+				return nil
+			}
+		}
+
+		n = child
+		input = input[k:]
 	}
 
 	return n
@@ -180,13 +573,27 @@ func (t *Trie) HasPrefix(prefix string) bool {
 	return t.SearchPrefix(prefix) != nil
 }
 
-// Autocomplete returns the keys that starts with "prefix",
-// this is useful for custom search-engines built on top of my trie implementation.
+// Autocomplete returns the keys that starts with "prefix", the prefix can end in the middle
+// of a segment or a parameter's name, e.g. "/cat" matches "/catalog/:category".
+// The keys are sorted lexically, then by the optional "sorter".
+// This is useful for custom search-engines built on top of my trie implementation.
 func (t *Trie) Autocomplete(prefix string, sorter NodeKeysSorter) (list []string) {
-	n := t.SearchPrefix(prefix)
-	if n != nil {
-		list = n.Keys(sorter)
+	keys := t.sortedKeys()
+	i := sort.SearchStrings(keys, prefix)
+	j := i
+	for j < len(keys) && strings.HasPrefix(keys[j], prefix) {
+		j++
+	}
+
+	if i == j {
+		return
 	}
+
+	list = append(list, keys[i:j]...)
+	if sorter != nil {
+		sort.SliceStable(list, sorter(list))
+	}
+
 	return
 }
 
@@ -207,48 +614,127 @@ type ParamsSetter interface {
 // 3. wildcards
 // 4. closest wildcard if not found, if any
 // 5. root wildcard
+//
+// The search commits to the first matching alternative of a segment,
+// see `SetBacktracking` to try the others when it does not lead to a pattern.
+//
+// The query of "q" selects the query variant of the found path, the most specific
+// matching one (see `Insert`), its captured values are set to the "params" too.
+// A variant does not match if the query has a key which is neither declared nor ignored
+// (see `SetQueryIgnore`), then the pattern without a query is used, if any.
+// The fragment of "q" is dropped, as well as the trailing slash of its path,
+// like `Insert` drops it, so "/catalog/shoes/" matches "/catalog/:category".
+// The static segments are compared byte-exact, see `SetCaseFolding` to ignore their case.
 func (t *Trie) Search(q string, params ParamsSetter) *Node {
+	return t.search(q, params, nil)
+}
+
+// search is the `Search` which records its steps to the "tr", if not nil, see `Explain`.
+func (t *Trie) search(q string, params ParamsSetter, tr *tracer) *Node {
+	q, rawQuery := splitURL(q)
+	q, values := foldPath(q, t.foldCase)
 	end := len(q)
 
 	if end == 0 || (end == 1 && q[0] == pathSepRune) {
 		// fixes only root wildcard but no / registered at.
 		if t.hasRootSlash {
-			return t.root.getChild(pathSep)
-		} else if t.hasRootWildcard {
+			n := t.root.getChild(pathSep)
+			if v := n.matchQuery(rawQuery, t.queryIgnore(), nil, params, tr); v != nil {
+				return v
+			}
+
+			if tr != nil {
+				tr.step(StepEnd, pathSep, n, n.end)
+			}
+
+			if n.end {
+				return n
+			}
+		}
+
+		if t.hasRootWildcard {
 			// no need to going through setting parameters, this one has not but it is wildcard.
-			return t.root.getChild(WildcardParamStart)
+			n := t.root.getChild(WildcardParamStart)
+			if tr != nil {
+				tr.step(StepRootWildcard, q, n, true)
+			}
+			return n
 		}
 
 		return nil
 	}
 
+	if t.backtracking {
+		return t.searchBacktracking(q, values, rawQuery, params, tr)
+	}
+
 	n := t.root
 	start := 1
 	i := 1
-	var paramValues []string
+	// most of the patterns have a few parameters,
+	// keep their values on the stack to not allocate on lookups.
+	var paramValuesBuf [8]string
+	paramValues := paramValuesBuf[:0]
 
 	for {
 		if i == end || q[i] == pathSepRune {
-			if child := n.getChild(q[start:i]); child != nil {
+			if child := n.getChild(q[start:i]); child != nil && isStaticLabel(child.label) {
+				if rest := child.label[i-start:]; rest != "" {
+					// the rest of a compressed edge, e.g. "/b/c" of "a/b/c",
+					// must be followed by the end of the query or a slash.
+					if !hasSegmentsPrefix(q[i:], rest) {
+						if tr != nil {
+							tr.step(StepStatic, values[start:], child, false)
+						}
+
+						if n = child.findClosestParentWildcardNode(); n != nil {
+							if tr != nil {
+								tr.step(StepClosestWildcard, values[len(n.staticKey):], n, true)
+							}
+							params.Set(n.paramKeys[0], values[len(n.staticKey):])
+							return n
+						}
+
+						return nil
+					}
+					i += len(rest)
+				}
+				if tr != nil {
+					tr.step(StepStatic, values[start:i], child, true)
+				}
 				n = child
-			} else if n.childNamedParameter { // && n.childWildcardParameter == false {
-				n = n.getChild(ParamStart)
+			} else if named := n.namedChild(values[start:i]); named != nil {
+				if tr != nil {
+					tr.step(StepNamed, values[start:i], named, true)
+				}
+				n = named
 				if ln := len(paramValues); cap(paramValues) > ln {
 					paramValues = paramValues[:ln+1]
-					paramValues[ln] = q[start:i]
+					paramValues[ln] = values[start:i]
 				} else {
-					paramValues = append(paramValues, q[start:i])
+					paramValues = append(paramValues, values[start:i])
 				}
 			} else if n.childWildcardParameter {
+				if tr != nil {
+					tr.traceNamed(n, values[start:i])
+				}
 				n = n.getChild(WildcardParamStart)
+				if tr != nil {
+					tr.step(StepWildcard, values[start:], n, true)
+				}
 				if ln := len(paramValues); cap(paramValues) > ln {
 					paramValues = paramValues[:ln+1]
-					paramValues[ln] = q[start:]
+					paramValues[ln] = values[start:]
 				} else {
-					paramValues = append(paramValues, q[start:])
+					paramValues = append(paramValues, values[start:])
 				}
 				break
 			} else {
+				if tr != nil {
+					tr.traceNamed(n, values[start:i])
+					tr.step(StepMiss, values[start:i], n, false)
+				}
+
 				n = n.findClosestParentWildcardNode()
 				if n != nil {
 					// means that it has :param/static and *wildcard, we go trhough the :param
@@ -264,7 +750,10 @@ func (t *Trie) Search(q string, params ParamsSetter) *Node {
 					// /second/wild/*p
 					// /second/wild/static/otherstatic/
 					// req: /second/wild/static/otherstatic/random => but not found!
-					params.Set(n.paramKeys[0], q[len(n.staticKey):])
+					if tr != nil {
+						tr.step(StepClosestWildcard, values[len(n.staticKey):], n, true)
+					}
+					params.Set(n.paramKeys[0], values[len(n.staticKey):])
 					return n
 				}
 
@@ -283,10 +772,23 @@ func (t *Trie) Search(q string, params ParamsSetter) *Node {
 		i++
 	}
 
+	if n != nil && n.queries != nil {
+		if v := n.matchQuery(rawQuery, t.queryIgnore(), paramValues, params, tr); v != nil {
+			return v
+		}
+	}
+
+	if tr != nil && n != nil {
+		tr.step(StepEnd, "", n, n.end)
+	}
+
 	if n == nil || !n.end {
 		if n != nil { // we need it on both places, on last segment (below) or on the first unnknown (above).
 			if n = n.findClosestParentWildcardNode(); n != nil {
-				params.Set(n.paramKeys[0], q[len(n.staticKey):])
+				if tr != nil {
+					tr.step(StepClosestWildcard, values[len(n.staticKey):], n, true)
+				}
+				params.Set(n.paramKeys[0], values[len(n.staticKey):])
 				return n
 			}
 		}
@@ -299,7 +801,10 @@ func (t *Trie) Search(q string, params ParamsSetter) *Node {
 			// Reqs: /other2/staticed will be handled
 			// by the /other2/*myparam and not the root wildcard (see above), which is what we want.
 			n = t.root.getChild(WildcardParamStart)
-			params.Set(n.paramKeys[0], q[1:])
+			if tr != nil {
+				tr.step(StepRootWildcard, values[1:], n, true)
+			}
+			params.Set(n.paramKeys[0], values[1:])
 			return n
 		}
 
@@ -314,3 +819,33 @@ func (t *Trie) Search(q string, params ParamsSetter) *Node {
 
 	return n
 }
+
+// hasSegmentsPrefix reports whether the "s" starts with the "rest" of an edge followed by the end or a slash.
+func hasSegmentsPrefix(s, rest string) bool {
+	return len(s) >= len(rest) && s[:len(rest)] == rest && (len(s) == len(rest) || s[len(rest)] == pathSepRune)
+}
+
+// foldPath returns the "path" to match the static segments against and the one to take the values from,
+// the lowercased path and the path itself if the "fold" is set. If lowercasing changes the length
+// of the path, the values can't be cut at the same positions, so they are lowercased too.
+func foldPath(path string, fold bool) (static, values string) {
+	if !fold {
+		return path, path
+	}
+
+	lower := strings.ToLower(path)
+	if len(lower) != len(path) {
+		return lower, lower
+	}
+
+	return lower, path
+}
+
+// nextSegment returns the start of the segment after the one which ends at "i" of the "q".
+func nextSegment(q string, i int) int {
+	if i < len(q) {
+		i++
+	}
+
+	return i
+}
@@ -0,0 +1,215 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func searchKey(t *Trie, q string) string {
+	n := t.Search(q, new(Params))
+	if n == nil {
+		return ""
+	}
+
+	return n.String()
+}
+
+func Test_Update(t *testing.T) {
+	t.Run("should replace data of existing pattern", func(t *testing.T) {
+		trie := NewTrie()
+		trie.Insert("/catalog/:category", WithData(&SeoData{MetaTitle: strPtr("old")}))
+
+		require.True(t, trie.Update("/catalog/:category", WithData(&SeoData{MetaTitle: strPtr("new")})))
+
+		n := trie.Search("/catalog/shoes", new(Params))
+		require.Equal(t, "new", *n.Data.MetaTitle)
+	})
+
+	t.Run("should not update unknown patterns", func(t *testing.T) {
+		trie := NewTrie()
+		trie.Insert("/catalog/:category/:brand")
+
+		require.False(t, trie.Update("/catalog/:category"))
+		require.False(t, trie.Update("/catalog/:slug/:brand"))
+		require.False(t, trie.Update(""))
+	})
+}
+
+func Test_Delete(t *testing.T) {
+	t.Run("should prune empty nodes", func(t *testing.T) {
+		trie := NewTrie()
+		trie.Insert("/a")
+		trie.Insert("/a/b/c/d")
+
+		require.True(t, trie.Delete("/a/b/c/d"))
+		require.Equal(t, "", searchKey(trie, "/a/b/c/d"))
+		require.Nil(t, trie.SearchPrefix("/a/b"))
+		require.Empty(t, trie.root.getChild("a").children)
+		require.Equal(t, "/a", searchKey(trie, "/a"))
+
+		require.False(t, trie.Delete("/a/b/c/d"))
+	})
+
+	t.Run("should keep children of deleted intermediate pattern", func(t *testing.T) {
+		trie := NewTrie()
+		trie.Insert("/a/:id")
+		trie.Insert("/a/:id/edit")
+
+		require.True(t, trie.Delete("/a/:id"))
+		require.Equal(t, "", searchKey(trie, "/a/1"))
+		require.Equal(t, "/a/:id/edit", searchKey(trie, "/a/1/edit"))
+	})
+
+	t.Run("should recompute dynamic child flags", func(t *testing.T) {
+		trie := NewTrie()
+		trie.Insert("/a/:id")
+		trie.Insert("/a/*rest")
+
+		require.True(t, trie.Delete("/a/:id"))
+
+		a := trie.root.getChild("a")
+		require.False(t, a.childNamedParameter)
+		require.True(t, a.childWildcardParameter)
+		require.True(t, a.hasDynamicChild)
+
+		params := new(Params)
+		require.Equal(t, "/a/*rest", trie.Search("/a/1", params).String())
+		require.Equal(t, "1", params.Get("rest"))
+
+		require.True(t, trie.Delete("/a/*rest"))
+		require.Nil(t, trie.root.getChild("a"))
+		require.False(t, trie.root.hasDynamicChild)
+	})
+
+	t.Run("should recompute root wildcard and root slash", func(t *testing.T) {
+		trie := NewTrie()
+		trie.Insert("/")
+		trie.Insert("/*any")
+		trie.Insert("/about")
+
+		require.Equal(t, "/*any", searchKey(trie, "/unknown"))
+
+		require.True(t, trie.Delete("/*any"))
+		require.False(t, trie.hasRootWildcard)
+		require.Equal(t, "", searchKey(trie, "/unknown"))
+		require.Equal(t, "/", searchKey(trie, "/"))
+
+		require.True(t, trie.Delete("/"))
+		require.False(t, trie.hasRootSlash)
+		require.Equal(t, "", searchKey(trie, "/"))
+		require.Equal(t, "/about", searchKey(trie, "/about"))
+	})
+
+	t.Run("should delete only exactly inserted pattern", func(t *testing.T) {
+		trie := NewTrie()
+		trie.Insert("/a/:id")
+
+		require.False(t, trie.Delete("/a/:slug"))
+		require.False(t, trie.Delete("/a"))
+		require.Equal(t, "/a/:id", searchKey(trie, "/a/1"))
+	})
+}
+
+func Test_Insert(t *testing.T) {
+	t.Run("should reject empty pattern", func(t *testing.T) {
+		require.ErrorIs(t, NewTrie().Insert(""), ErrEmptyPattern)
+	})
+
+	t.Run("should return conflict error and keep the first pattern", func(t *testing.T) {
+		trie := NewTrie()
+		require.Nil(t, trie.Insert("/a/:id", WithData(&SeoData{MetaTitle: strPtr("first")})))
+
+		for _, pattern := range []string{"/a/:slug", "/a/:id", "/a/:id/"} {
+			err := trie.Insert(pattern, WithData(&SeoData{MetaTitle: strPtr("second")}))
+
+			var conflict *ConflictError
+			require.ErrorAs(t, err, &conflict, pattern)
+			require.Equal(t, pattern, conflict.Pattern)
+			require.Equal(t, "/a/:id", conflict.Existing)
+		}
+
+		params := new(Params)
+		n := trie.Search("/a/1", params)
+		require.Equal(t, "/a/:id", n.String())
+		require.Equal(t, "first", *n.Data.MetaTitle)
+		require.Equal(t, "1", params.Get("id"))
+	})
+
+	t.Run("should not conflict with intermediate nodes", func(t *testing.T) {
+		trie := NewTrie()
+		require.Nil(t, trie.Insert("/a/:id/edit"))
+		require.Nil(t, trie.Insert("/a/:slug"))
+		require.Nil(t, trie.Insert("/a/*rest"))
+
+		require.Equal(t, "/a/:slug", searchKey(trie, "/a/1"))
+		require.Equal(t, "/a/:id/edit", searchKey(trie, "/a/1/edit"))
+	})
+}
+
+func Test_SearchTrailingSlash(t *testing.T) {
+	trie := NewTrie()
+	for _, p := range []string{"/catalog/", "/catalog/:category/", "/w/*rest"} {
+		require.Nil(t, trie.Insert(p))
+	}
+
+	for _, backtracking := range []bool{false, true} {
+		trie.SetBacktracking(backtracking)
+
+		p := new(Params)
+		require.Equal(t, "/catalog/", trie.Search("/catalog/", p).String())
+		require.Empty(t, p.Map())
+
+		p = new(Params)
+		require.Equal(t, "/catalog/:category/", trie.Search("/catalog/shoes/?page=2", p).String())
+		require.Equal(t, map[string]string{"category": "shoes"}, p.Map())
+
+		p = new(Params)
+		require.Equal(t, "/w/*rest", trie.Search("/w/a/b/", p).String())
+		require.Equal(t, map[string]string{"rest": "a/b"}, p.Map())
+
+		p = new(Params)
+		require.Equal(t, "/w/*rest", trie.Freeze().Search("/w/a/b/", p).String())
+		require.Equal(t, map[string]string{"rest": "a/b"}, p.Map())
+	}
+}
+
+func Test_SearchCaseFolding(t *testing.T) {
+	trie := NewTrie()
+	for _, p := range []string{"/catalog/:code<[A-Z]{2}>", "/catalog/sale/items", "/blog/*path", "/товары/:slug"} {
+		require.Nil(t, trie.Insert(p))
+	}
+
+	require.Nil(t, trie.Search("/Catalog/RU", new(Params)))
+
+	trie.SetCaseFolding(true)
+	tests := []struct {
+		url     string
+		pattern string
+		params  map[string]string
+	}{
+		{"/Catalog/RU", "/catalog/:code<[A-Z]{2}>", map[string]string{"code": "RU"}},
+		{"/catalog/ru", "", nil},
+		{"/CATALOG/Sale/Items", "/catalog/sale/items", map[string]string{}},
+		{"/Blog/Red/Shoes", "/blog/*path", map[string]string{"path": "Red/Shoes"}},
+		{"/Товары/Обувь", "/товары/:slug", map[string]string{"slug": "Обувь"}},
+	}
+
+	for _, backtracking := range []bool{false, true} {
+		trie.SetBacktracking(backtracking)
+		frozen := trie.Freeze()
+
+		for _, tt := range tests {
+			p := new(Params)
+			n := trie.Search(tt.url, p)
+			requireFrozenSearch(t, frozen, tt.url, tt.pattern, tt.params)
+			if tt.pattern == "" {
+				require.Nil(t, n, tt.url)
+				continue
+			}
+
+			require.NotNil(t, n, tt.url)
+			require.Equal(t, tt.pattern, n.String(), tt.url)
+			require.Equal(t, tt.params, p.Map(), tt.url)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package radixtrie
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL builds the url of the inserted "pattern" with the "params" values, see `Node.URL`.
+// The "pattern" is resolved structurally: the constraints of its named parameters
+// and the order of its query keys may differ from the inserted ones, e.g. "/c/:id"
+// resolves to the inserted "/c/:id<int>", but the parameter names must be the same.
+// It returns `ErrUnknownPattern` if the pattern was not inserted.
+func (t *Trie) URL(pattern string, params map[string]string) (string, error) {
+	n := t.findURL(pattern)
+	if n == nil {
+		return "", fmt.Errorf("%w %q", ErrUnknownPattern, pattern)
+	}
+
+	return n.URL(params)
+}
+
+// findURL returns the inserted pattern's node the "pattern" resolves to, see `Trie.URL`.
+func (t *Trie) findURL(pattern string) *Node {
+	path, rawQuery, hasQuery := strings.Cut(pattern, QueryStart)
+	n := t.walk(path)
+	if n == nil {
+		return nil
+	}
+
+	if hasQuery {
+		q, err := parseQueryPattern(rawQuery)
+		if err != nil {
+			return nil
+		}
+
+		for _, v := range n.queries {
+			if v.query.canonical == q.canonical && samePath(v.key, path) {
+				return v
+			}
+		}
+
+		return nil
+	}
+
+	if !n.end || !samePath(n.key, path) {
+		return nil
+	}
+
+	return n
+}
+
+// samePath reports whether the path of the "key" and the "path" are the same pattern,
+// regardless of the constraints of their named parameters.
+func samePath(key, path string) bool {
+	key, _, _ = strings.Cut(key, QueryStart)
+	return stripConstraints(key) == stripConstraints(path)
+}
+
+func stripConstraints(path string) string {
+	if !strings.Contains(path, ParamStart) {
+		return path
+	}
+
+	segments := strings.Split(path, pathSep)
+	for i, s := range segments {
+		if strings.HasPrefix(s, ParamStart) {
+			name, _, _ := splitConstraint(s[1:])
+			segments[i] = ParamStart + name
+		}
+	}
+
+	return strings.Join(segments, pathSep)
+}
+
+// URL is the reverse of `Trie.Search`: it renders the node's pattern with the "params" values, e.g.
+// /catalog/:category/*rest + {category: shoes, rest: red/42} -> /catalog/shoes/red/42.
+// The named parameters' values are path escaped, the wildcard's value is escaped segment by segment.
+// The query keys are rendered in the canonical form, see `Node.CanonicalQuery`.
+// It returns `ErrMissingParam` if a parameter or a required query key has no value
+// and `ErrInvalidParam` if a value does not satisfy the parameter's constraint
+// or a value's segment is a dot segment ("." or ".."), which the clients would resolve.
+// The params which are not declared by the pattern are ignored.
+func (n *Node) URL(params map[string]string) (string, error) {
+	path := n
+	if n.query != nil {
+		path = n.parent
+	}
+
+	// the constraints of the named parameters, in the order of the path segments.
+	var constraints []*constraint
+	for p := path; p != nil; p = p.parent {
+		if p.label == ParamStart {
+			constraints = append(constraints, p.constraint)
+		}
+	}
+
+	pattern, _, _ := strings.Cut(n.key, QueryStart)
+
+	var b strings.Builder
+	if pattern == pathSep {
+		b.WriteString(pathSep)
+	} else {
+		for _, s := range slowPathSplit(pattern) {
+			b.WriteString(pathSep)
+
+			if !isDynamicSegment(s) {
+				b.WriteString(s)
+				continue
+			}
+
+			name, _, _ := splitConstraint(s[1:])
+			value := params[name]
+			if value == "" {
+				return "", fmt.Errorf("%w %q", ErrMissingParam, name)
+			}
+
+			if s[0] == WildcardParamStart[0] {
+				for i, part := range strings.Split(value, pathSep) {
+					if isDotSegment(part) {
+						return "", fmt.Errorf("%w %q: %q is a dot segment", ErrInvalidParam, name, part)
+					}
+
+					if i > 0 {
+						b.WriteString(pathSep)
+					}
+					b.WriteString(url.PathEscape(part))
+				}
+				continue
+			}
+
+			if isDotSegment(value) {
+				return "", fmt.Errorf("%w %q: %q is a dot segment", ErrInvalidParam, name, value)
+			}
+
+			c := constraints[len(constraints)-1]
+			constraints = constraints[:len(constraints)-1]
+			if c != nil && !c.match(value) {
+				return "", fmt.Errorf("%w %q: %q does not match %q", ErrInvalidParam, name, value, c)
+			}
+			b.WriteString(url.PathEscape(value))
+		}
+	}
+
+	if n.query == nil {
+		return b.String(), nil
+	}
+
+	for _, k := range n.query.keys {
+		if !k.exact && !k.optional && params[k.name] == "" {
+			return "", fmt.Errorf("%w %q", ErrMissingParam, k.name)
+		}
+	}
+
+	if query := n.query.canonicalQuery(paramsMap(params)); query != "" {
+		b.WriteString(QueryStart)
+		b.WriteString(query)
+	}
+
+	return b.String(), nil
+}
+
+func isDotSegment(s string) bool {
+	return s == "." || s == ".."
+}
+
+// paramsMap is the `ParamsGetter` of a map.
+type paramsMap map[string]string
+
+func (m paramsMap) Get(key string) string {
+	return m[key]
+}
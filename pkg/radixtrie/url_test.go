@@ -0,0 +1,89 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_URL(t *testing.T) {
+	trie := NewTrie()
+	for _, p := range []string{
+		"/",
+		"/about",
+		"/catalog/:category",
+		"/catalog/:category/*rest",
+		"/catalog/:category?color&size=42&sort?",
+		"/product/:id<int>/:lang<enum:ru,en>",
+		"/*path",
+	} {
+		require.Nil(t, trie.Insert(p))
+	}
+
+	tests := []struct {
+		pattern string
+		params  map[string]string
+		url     string
+	}{
+		{"/", nil, "/"},
+		{"/about", map[string]string{"unused": "x"}, "/about"},
+		{"/catalog/:category", map[string]string{"category": "shoes"}, "/catalog/shoes"},
+		{"/catalog/:category", map[string]string{"category": "a b/c?d"}, "/catalog/a%20b%2Fc%3Fd"},
+		{"/catalog/:category/*rest", map[string]string{"category": "shoes", "rest": "red/42 xl"}, "/catalog/shoes/red/42%20xl"},
+		{"/catalog/:category?color&size=42&sort?", map[string]string{"category": "shoes", "color": "red&blue"}, "/catalog/shoes?color=red%26blue&size=42"},
+		{"/catalog/:category?color&size=42&sort?", map[string]string{"category": "shoes", "color": "red", "sort": "price"}, "/catalog/shoes?color=red&size=42&sort=price"},
+		{"/product/:id<int>/:lang<enum:ru,en>", map[string]string{"id": "42", "lang": "en"}, "/product/42/en"},
+		{"/product/:id/:lang", map[string]string{"id": "42", "lang": "en"}, "/product/42/en"},
+		{"/catalog/:category?sort?&size=42&color", map[string]string{"category": "shoes", "color": "red"}, "/catalog/shoes?color=red&size=42"},
+		{"/*path", map[string]string{"path": "a/b"}, "/a/b"},
+	}
+
+	for _, tt := range tests {
+		actual, err := trie.URL(tt.pattern, tt.params)
+		require.Nil(t, err, tt.pattern)
+		require.Equal(t, tt.url, actual, tt.pattern)
+	}
+
+	t.Run("should be matched by the pattern", func(t *testing.T) {
+		params := map[string]string{"category": "shoes", "rest": "red/42"}
+		u, err := trie.URL("/catalog/:category/*rest", params)
+		require.Nil(t, err)
+
+		p := new(Params)
+		require.Equal(t, "/catalog/:category/*rest", trie.Search(u, p).String())
+		require.Equal(t, params, p.Map())
+	})
+
+	t.Run("should return errors", func(t *testing.T) {
+		_, err := trie.URL("/unknown", nil)
+		require.ErrorIs(t, err, ErrUnknownPattern)
+
+		_, err = trie.URL("/catalog/:slug", map[string]string{"slug": "shoes"})
+		require.ErrorIs(t, err, ErrUnknownPattern)
+
+		_, err = trie.URL("/catalog/:category/*rest", map[string]string{"category": "shoes"})
+		require.ErrorIs(t, err, ErrMissingParam)
+
+		_, err = trie.URL("/catalog/:category?color&size=42&sort?", map[string]string{"category": "shoes"})
+		require.ErrorIs(t, err, ErrMissingParam)
+
+		_, err = trie.URL("/product/:id<int>/:lang<enum:ru,en>", map[string]string{"id": "x", "lang": "en"})
+		require.ErrorIs(t, err, ErrInvalidParam)
+
+		_, err = trie.URL("/product/:id<int>/:lang<enum:ru,en>", map[string]string{"id": "1", "lang": "de"})
+		require.ErrorIs(t, err, ErrInvalidParam)
+
+		_, err = trie.URL("/product/:id", map[string]string{"id": "1"})
+		require.ErrorIs(t, err, ErrUnknownPattern)
+
+		_, err = trie.URL("/catalog/:category?color", map[string]string{"category": "shoes", "color": "red"})
+		require.ErrorIs(t, err, ErrUnknownPattern)
+
+		for _, value := range []string{".", ".."} {
+			_, err = trie.URL("/catalog/:category", map[string]string{"category": value})
+			require.ErrorIs(t, err, ErrInvalidParam, value)
+
+			_, err = trie.URL("/catalog/:category/*rest", map[string]string{"category": "shoes", "rest": "a/" + value + "/b"})
+			require.ErrorIs(t, err, ErrInvalidParam, value)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package radixtrie
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEmptyPattern is returned by `ValidatePattern` and `Trie.Insert` when the pattern is empty.
+var ErrEmptyPattern = errors.New("empty pattern")
+
+// ValidatePattern reports whether the "pattern" can be safely passed to `Trie.Insert`.
+// A valid pattern starts with a slash, has no empty segments (a single trailing slash is allowed),
+// declares named parameters (:name or constrained :name<spec>) and wildcards (*name) only at the beginning of a segment,
+// has unique parameter names and keeps the wildcard as the last segment.
+// The optional query of the pattern must declare unique keys, which don't clash
+// with the path parameters, see `Trie.Insert`.
+func ValidatePattern(pattern string) error {
+	if pattern == "" {
+		return ErrEmptyPattern
+	}
+
+	if pattern[0] != pathSepRune {
+		return fmt.Errorf("pattern %q must start with %q", pattern, pathSep)
+	}
+
+	path, rawQuery, hasQuery := strings.Cut(pattern, QueryStart)
+	if strings.IndexByte(path, fragmentStart) != -1 {
+		return fmt.Errorf("pattern %q must not contain a fragment", pattern)
+	}
+
+	seen := make(map[string]struct{})
+	if hasQuery {
+		query, err := parseQueryPattern(rawQuery)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+
+		for _, name := range query.captures {
+			seen[name] = struct{}{}
+		}
+	}
+
+	if path == pathSep {
+		return nil
+	}
+
+	segments := slowPathSplit(path)
+
+	for i, s := range segments {
+		if s == "" {
+			return fmt.Errorf("pattern %q contains an empty segment", pattern)
+		}
+
+		isParam, isWildcard := s[0] == ParamStart[0], s[0] == WildcardParamStart[0]
+		if !isParam && !isWildcard {
+			if strings.Contains(s, ParamStart) || strings.Contains(s, WildcardParamStart) {
+				return fmt.Errorf("pattern %q: segment %q mixes static part and parameter", pattern, s)
+			}
+			continue
+		}
+
+		name, spec, ok := splitConstraint(s[1:])
+		if !ok {
+			return fmt.Errorf("pattern %q: invalid constraint in segment %q", pattern, s)
+		}
+
+		if name == "" {
+			return fmt.Errorf("pattern %q: parameter name is required in segment %q", pattern, s)
+		}
+
+		if spec != "" {
+			if isWildcard {
+				return fmt.Errorf("pattern %q: wildcard %q can't be constrained", pattern, s)
+			}
+
+			if _, err := parseConstraint(spec); err != nil {
+				return fmt.Errorf("pattern %q: %w", pattern, err)
+			}
+		}
+
+		if strings.ContainsAny(name, ParamStart+WildcardParamStart+string(constraintEnd)) {
+			return fmt.Errorf("pattern %q: invalid parameter name %q", pattern, name)
+		}
+
+		if _, exists := seen[name]; exists {
+			return fmt.Errorf("pattern %q: duplicate parameter name %q", pattern, name)
+		}
+		seen[name] = struct{}{}
+
+		if isWildcard && i != len(segments)-1 {
+			return fmt.Errorf("pattern %q: wildcard %q must be the last segment", pattern, s)
+		}
+	}
+
+	return nil
+}
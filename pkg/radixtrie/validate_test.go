@@ -0,0 +1,38 @@
+package radixtrie
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_ValidatePattern(t *testing.T) {
+	t.Run("should accept valid patterns", func(t *testing.T) {
+		for _, pattern := range []string{
+			"/",
+			"/catalog",
+			"/catalog/",
+			"/catalog/:category",
+			"/catalog/:category/*rest",
+			"/*any",
+		} {
+			require.Nil(t, ValidatePattern(pattern), pattern)
+		}
+	})
+
+	t.Run("should reject empty pattern", func(t *testing.T) {
+		require.ErrorIs(t, ValidatePattern(""), ErrEmptyPattern)
+	})
+
+	t.Run("should reject invalid patterns", func(t *testing.T) {
+		for pattern, reason := range map[string]string{
+			"catalog":           "must start with",
+			"/catalog//shoes":   "empty segment",
+			"/catalog/shoes:42": "mixes static part and parameter",
+			"/catalog/:":        "parameter name is required",
+			"/a/:id/b/:id":      "duplicate parameter name",
+			"/a/*rest/b":        "must be the last segment",
+		} {
+			require.ErrorContains(t, ValidatePattern(pattern), reason, pattern)
+		}
+	})
+}
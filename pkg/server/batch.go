@@ -0,0 +1,73 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/quadgod/seo/pkg/locale"
+	"net/http"
+)
+
+const (
+	// MaxBatchSize максимальное количество url в одном batch запросе
+	MaxBatchSize = 1000
+	// maxBatchBodySize ограничение размера тела batch запроса
+	maxBatchBodySize = 4 << 20
+)
+
+// BatchItem результат поиска одного url из batch запроса.
+// Если url не найден, Found = false, а поля Result отсутствуют
+type BatchItem struct {
+	URL   string `json:"url"`
+	Found bool   `json:"found"`
+	Error string `json:"error,omitempty"`
+	*Result
+}
+
+// batch принимает JSON массив url и возвращает массив результатов в том же порядке.
+// Все url ищутся в одном снапшоте, поэтому относятся к одной генерации.
+// Параметр locale и заголовок Accept-Language запроса применяются ко всем url
+func (s *Server) batch(w http.ResponseWriter, r *http.Request) {
+	var urls []string
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodySize)).Decode(&urls); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	if len(urls) > MaxBatchSize {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("batch size must not exceed %d urls", MaxBatchSize))
+		return
+	}
+
+	explicitLocale, acceptLanguage := r.URL.Query().Get("locale"), r.Header.Get("Accept-Language")
+	if _, err := locale.Parse(explicitLocale); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snap := s.snapshot(w)
+	if snap == nil {
+		return
+	}
+
+	items := make([]BatchItem, len(urls))
+	for i, url := range urls {
+		items[i].URL = url
+
+		host, normalized, err := s.normalize(url)
+		if err != nil {
+			items[i].Error = err.Error()
+			continue
+		}
+
+		match, err := s.locales.Resolve(explicitLocale, acceptLanguage, normalized)
+		if err != nil {
+			items[i].Error = err.Error()
+			continue
+		}
+
+		items[i].Result = s.find(snap, host, match)
+		items[i].Found = items[i].Result != nil
+	}
+
+	writeJson(w, http.StatusOK, items)
+}
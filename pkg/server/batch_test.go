@@ -0,0 +1,80 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func post(t *testing.T, url string, body string) (*http.Response, []byte) {
+	res, err := http.Post(url, "application/json", strings.NewReader(body))
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	buf := new(bytes.Buffer)
+	_, err = buf.ReadFrom(res.Body)
+	require.Nil(t, err)
+
+	return res, buf.Bytes()
+}
+
+func Test_Batch(t *testing.T) {
+	generation := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	trie := radixtrie.NewTrie()
+	trie.Insert("/catalog/:category", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: strPtr("category")}))
+	trie.Insert("/about", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: strPtr("about")}))
+
+	srv := newTestServer(t, trie, generation)
+
+	t.Run("should resolve urls preserving order and not found items", func(t *testing.T) {
+		res, body := post(t, srv.URL+"/seo/batch", `["/about", "/unknown", "/catalog/shoes", "about"]`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "2025-03-20T12:00:00Z", res.Header.Get(GenerationHeader))
+
+		var items []BatchItem
+		require.Nil(t, json.Unmarshal(body, &items))
+		require.Len(t, items, 4)
+
+		require.Equal(t, "/about", items[0].URL)
+		require.True(t, items[0].Found)
+		require.Equal(t, "about", *items[0].Data.MetaTitle)
+
+		require.Equal(t, "/unknown", items[1].URL)
+		require.False(t, items[1].Found)
+		require.Nil(t, items[1].Result)
+
+		require.Equal(t, "/catalog/shoes", items[2].URL)
+		require.True(t, items[2].Found)
+		require.Equal(t, "/catalog/:category", items[2].Pattern)
+		require.Equal(t, map[string]string{"category": "shoes"}, items[2].Params)
+
+		require.False(t, items[3].Found)
+		require.NotEmpty(t, items[3].Error)
+	})
+
+	t.Run("should omit result fields for not found items", func(t *testing.T) {
+		_, body := post(t, srv.URL+"/seo/batch", `["/unknown"]`)
+		require.JSONEq(t, `[{"url": "/unknown", "found": false}]`, string(body))
+	})
+
+	t.Run("should reject invalid body and too large batches", func(t *testing.T) {
+		res, _ := post(t, srv.URL+"/seo/batch", `{"url": "/about"}`)
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		urls := make([]string, MaxBatchSize+1)
+		for i := range urls {
+			urls[i] = "/about"
+		}
+		body, err := json.Marshal(urls)
+		require.Nil(t, err)
+
+		res, _ = post(t, srv.URL+"/seo/batch", string(body))
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
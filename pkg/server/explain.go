@@ -0,0 +1,90 @@
+package server
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"net/http"
+)
+
+// ExplainStep шаг поиска url в дереве сайта, см. radixtrie.Step
+type ExplainStep struct {
+	Kind    radixtrie.StepKind `json:"kind"`
+	Segment string             `json:"segment"`
+	Edge    string             `json:"edge,omitempty"`
+	Pattern string             `json:"pattern,omitempty"`
+	Matched bool               `json:"matched"`
+}
+
+// SiteExplanation поиск url в дереве одного сайта: найденный шаблон (пустой, если не найден),
+// шаги поиска и все шаблоны сайта, подходящие под url, в порядке приоритета поиска с возвратом
+type SiteExplanation struct {
+	Site       string        `json:"site"`
+	Pattern    string        `json:"pattern,omitempty"`
+	Steps      []ExplainStep `json:"steps"`
+	Candidates []string      `json:"candidates"`
+}
+
+// Explanation отладочный ответ поиска url: цепочка локалей, поиск в деревьях сайтов хоста
+// в порядке их перебора (см. site.Sites.Resolve) и итоговый результат, как его вернул бы /seo (nil, если не найден)
+type Explanation struct {
+	URL     string            `json:"normalizedUrl"`
+	Locales []string          `json:"locales"`
+	Sites   []SiteExplanation `json:"sites"`
+	Result  *Result           `json:"result"`
+}
+
+// explain отладочный эндпоинт: принимает те же параметры, что и /seo, и объясняет,
+// почему url нашел свой шаблон, какие шаги сделал поиск и какие еще шаблоны могли подойти.
+// Поиск с записью шагов медленный, поэтому эндпоинт не предназначен для боевого трафика
+func (s *Server) explain(w http.ResponseWriter, r *http.Request) {
+	host, url, err := s.normalize(r.URL.Query().Get("url"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	match, err := s.locales.Resolve(r.URL.Query().Get("locale"), r.Header.Get("Accept-Language"), url)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snap := s.snapshot(w)
+	if snap == nil {
+		return
+	}
+
+	explanation := Explanation{
+		URL:     match.URL,
+		Locales: match.Chain,
+		Sites:   []SiteExplanation{},
+		Result:  s.search(snap.Sites, host, match),
+	}
+
+	for matched, trie := range snap.Sites.Resolve(host) {
+		explanation.Sites = append(explanation.Sites, newSiteExplanation(matched, trie.Explain(match.URL, new(radixtrie.Params))))
+	}
+
+	writeJson(w, http.StatusOK, explanation)
+}
+
+func newSiteExplanation(host string, e *radixtrie.Explanation) SiteExplanation {
+	result := SiteExplanation{
+		Site:       host,
+		Steps:      make([]ExplainStep, 0, len(e.Steps)),
+		Candidates: make([]string, 0, len(e.Candidates)),
+	}
+
+	if e.Node != nil {
+		result.Pattern = e.Node.String()
+	}
+
+	for _, step := range e.Steps {
+		result.Steps = append(result.Steps, ExplainStep(step))
+	}
+
+	for _, n := range e.Candidates {
+		result.Candidates = append(result.Candidates, n.String())
+	}
+
+	return result
+}
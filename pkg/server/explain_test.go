@@ -0,0 +1,93 @@
+package server
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func Test_Explain(t *testing.T) {
+	sites := site.New(radixtrie.NewTrie())
+	require.Nil(t, sites.Default().Insert("/catalog/*rest", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: strPtr("rest")})))
+	require.Nil(t, sites.Add("shop.example.ru").Insert("/catalog/:category/new", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: strPtr("new")})))
+	require.Nil(t, sites.Add("shop.example.ru").Insert("/catalog/:category/:id<int>", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: strPtr("product")})))
+
+	srv := newAdminTestServer(t, sites, time.Now())
+
+	t.Run("should explain search in every site of host", func(t *testing.T) {
+		res, body := get(t, srv, "/seo/debug/explain", url.Values{"url": {"https://shop.example.ru/catalog/shoes/red"}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.JSONEq(t, `{
+			"normalizedUrl": "/catalog/shoes/red",
+			"locales": [""],
+			"sites": [
+				{
+					"site": "shop.example.ru",
+					"steps": [
+						{"kind": "static", "segment": "catalog", "edge": "catalog", "matched": true},
+						{"kind": "named", "segment": "shoes", "edge": ":", "matched": true},
+						{"kind": "named", "segment": "red", "edge": ":<int>", "pattern": "/catalog/:category/:id<int>", "matched": false},
+						{"kind": "miss", "segment": "red", "edge": ":", "matched": false}
+					],
+					"candidates": []
+				},
+				{
+					"site": "",
+					"pattern": "/catalog/*rest",
+					"steps": [
+						{"kind": "static", "segment": "catalog", "edge": "catalog", "matched": true},
+						{"kind": "wildcard", "segment": "shoes/red", "edge": "*", "pattern": "/catalog/*rest", "matched": true},
+						{"kind": "end", "segment": "", "edge": "*", "pattern": "/catalog/*rest", "matched": true}
+					],
+					"candidates": ["/catalog/*rest"]
+				}
+			],
+			"result": {
+				"normalizedUrl": "/catalog/shoes/red",
+				"pattern": "/catalog/*rest",
+				"params": {"rest": "shoes/red"},
+				"data": {
+					"metaTitle": "rest",
+					"metaDescription": null,
+					"metaRobots": null,
+					"metaKeywords": null,
+					"metaHeader": null,
+					"canonicalLink": null,
+					"ogTitle": null,
+					"ogDescription": null,
+					"ogImage": null,
+					"ogType": null,
+					"faq": null,
+					"tagsCloud": null,
+					"hreflang": null
+				}
+			}
+		}`, string(body))
+	})
+
+	t.Run("should explain url which is not found", func(t *testing.T) {
+		res, body := get(t, srv, "/seo/debug/explain", url.Values{"url": {"/about"}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.JSONEq(t, `{
+			"normalizedUrl": "/about",
+			"locales": [""],
+			"sites": [
+				{
+					"site": "",
+					"steps": [{"kind": "miss", "segment": "about", "matched": false}],
+					"candidates": []
+				}
+			],
+			"result": null
+		}`, string(body))
+	})
+
+	t.Run("should return 400 for invalid url", func(t *testing.T) {
+		res, _ := get(t, srv, "/seo/debug/explain", url.Values{})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
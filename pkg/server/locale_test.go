@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/quadgod/seo/pkg/locale"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func Test_LookupLocale(t *testing.T) {
+	trie := radixtrie.NewTrie()
+	require.Nil(t, trie.InsertLocale("/catalog/:category?color", "", &radixtrie.SeoData{MetaTitle: strPtr("каталог")}))
+	require.Nil(t, trie.InsertLocale("/catalog/:category?color", "en", &radixtrie.SeoData{MetaTitle: strPtr("catalog")}))
+	require.Nil(t, trie.InsertLocale("/catalog/:category?color", "en-gb", &radixtrie.SeoData{MetaTitle: strPtr("catalogue")}))
+	require.Nil(t, trie.InsertLocale("/about", "", &radixtrie.SeoData{MetaTitle: strPtr("о нас")}))
+
+	srv := newLocalesTestServer(t, site.New(trie), time.Now(), &locale.Resolver{Default: "ru", Prefixes: []string{"en"}})
+
+	lookup := func(query url.Values, acceptLanguage string) Result {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/seo?"+query.Encode(), nil)
+		require.Nil(t, err)
+		req.Header.Set("Accept-Language", acceptLanguage)
+
+		res, err := http.DefaultClient.Do(req)
+		require.Nil(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var result Result
+		require.Nil(t, json.NewDecoder(res.Body).Decode(&result))
+		return result
+	}
+
+	t.Run("should resolve locale from prefix and return alternates", func(t *testing.T) {
+		result := lookup(url.Values{"url": {"/en/catalog/shoes?utm_source=mail&color=red"}}, "")
+		require.Equal(t, "en", result.Locale)
+		require.Equal(t, "catalog", *result.Data.MetaTitle)
+		require.Equal(t, "/catalog/shoes?utm_source=mail&color=red", result.URL)
+		require.Equal(t, []Alternate{
+			{Locale: "en", Href: "/en/catalog/shoes?color=red"},
+			{Locale: "en-gb", Href: "/catalog/shoes?color=red"},
+			{Locale: "ru", Href: "/catalog/shoes?color=red"},
+			{Locale: XDefault, Href: "/catalog/shoes?color=red"},
+		}, result.Alternates)
+	})
+
+	t.Run("should prefer explicit locale over accept-language", func(t *testing.T) {
+		result := lookup(url.Values{"url": {"/catalog/shoes?color=red"}}, "en-GB, en;q=0.8")
+		require.Equal(t, "en-gb", result.Locale)
+		require.Equal(t, "catalogue", *result.Data.MetaTitle)
+
+		result = lookup(url.Values{"url": {"/catalog/shoes?color=red"}, "locale": {"en-US"}}, "en-GB")
+		require.Equal(t, "en", result.Locale)
+		require.Equal(t, "catalog", *result.Data.MetaTitle)
+	})
+
+	t.Run("should fall back to neutral data as default locale", func(t *testing.T) {
+		result := lookup(url.Values{"url": {"/catalog/shoes?color=red"}}, "de")
+		require.Equal(t, "ru", result.Locale)
+		require.Equal(t, "каталог", *result.Data.MetaTitle)
+
+		result = lookup(url.Values{"url": {"/en/about"}}, "")
+		require.Equal(t, "ru", result.Locale)
+		require.Equal(t, "о нас", *result.Data.MetaTitle)
+		require.Nil(t, result.Alternates)
+	})
+
+	t.Run("should return 400 for invalid locale", func(t *testing.T) {
+		res, _ := get(t, srv, "/seo", url.Values{"url": {"/about"}, "locale": {"english"}})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		res, _ = post(t, srv.URL+"/seo/batch?locale=english", `["/about"]`)
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should resolve locale of batch urls", func(t *testing.T) {
+		res, body := post(t, srv.URL+"/seo/batch?locale=en", `["/catalog/shoes?color=red", "/en/about"]`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var items []BatchItem
+		require.Nil(t, json.Unmarshal(body, &items))
+		require.Equal(t, "catalog", *items[0].Data.MetaTitle)
+		require.Equal(t, "о нас", *items[1].Data.MetaTitle)
+	})
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics метрики поиска текущей генерации. RootWildcardFallbacks - число url запросов /seo и /seo/batch
+// по сайтам (пустой ключ - сайт по умолчанию), которые не подошли ни под один шаблон, кроме корневого wildcard (/*path).
+// Счетчики сбрасываются при переходе на новую генерацию, отладочные запросы не учитываются
+type Metrics struct {
+	Generation            time.Time         `json:"generation"`
+	RootWildcardFallbacks map[string]uint64 `json:"rootWildcardFallbacks"`
+}
+
+// fallbackCounter счетчики корневого wildcard одной генерации по сайтам
+type fallbackCounter struct {
+	generation time.Time
+	sites      sync.Map // сайт -> *atomic.Uint64
+}
+
+// Metrics возвращает метрики поиска текущей генерации
+func (s *Server) Metrics() Metrics {
+	m := Metrics{RootWildcardFallbacks: map[string]uint64{}}
+
+	c := s.fallbacks.Load()
+	if c == nil {
+		return m
+	}
+
+	m.Generation = c.generation
+	c.sites.Range(func(site, count any) bool {
+		m.RootWildcardFallbacks[site.(string)] = count.(*atomic.Uint64).Load()
+		return true
+	})
+
+	return m
+}
+
+// countRootWildcard учитывает url сайта, найденный корневым wildcard в генерации.
+// Счетчики более новой генерации заменяют текущие, запросы к предыдущей генерации,
+// завершившиеся после ее замены, не учитываются
+func (s *Server) countRootWildcard(generation time.Time, site string) {
+	c := s.fallbacks.Load()
+	for c == nil || !c.generation.Equal(generation) {
+		if c != nil && generation.Before(c.generation) {
+			return
+		}
+
+		next := &fallbackCounter{generation: generation}
+		if s.fallbacks.CompareAndSwap(c, next) {
+			c = next
+			break
+		}
+
+		c = s.fallbacks.Load()
+	}
+
+	count, ok := c.sites.Load(site)
+	if !ok {
+		count, _ = c.sites.LoadOrStore(site, new(atomic.Uint64))
+	}
+
+	count.(*atomic.Uint64).Add(1)
+}
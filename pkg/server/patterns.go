@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"net/http"
+	"strconv"
+)
+
+// MaxPatternsLimit максимальный размер страницы списка шаблонов
+const MaxPatternsLimit = 1000
+
+// PatternsPage страница списка объявленных шаблонов генерации в лексическом порядке.
+// Next - курсор следующей страницы (параметр after), пустой на последней странице
+type PatternsPage struct {
+	Patterns []string `json:"patterns"`
+	Next     string   `json:"next,omitempty"`
+}
+
+// patterns отдает страницу шаблонов сайта текущей генерации для админки.
+// host - хост или шаблон хоста сайта, как в декларациях (по умолчанию - сайт по умолчанию),
+// prefix - произвольный префикс шаблона, в том числе часть сегмента ("/cat" найдет "/catalog/:category"),
+// after - курсор из Next предыдущей страницы, limit - размер страницы (по умолчанию radixtrie.DefaultListLimit)
+func (s *Server) patterns(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := radixtrie.DefaultListLimit
+	if raw := query.Get("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit <= 0 || limit > MaxPatternsLimit {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be a number from 1 to %d", MaxPatternsLimit))
+			return
+		}
+	}
+
+	host, err := site.ParseHost(query.Get("host"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snap := s.snapshot(w)
+	if snap == nil {
+		return
+	}
+
+	trie := snap.Sites.Trie(host)
+	if trie == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("unknown site %q", host))
+		return
+	}
+
+	page := trie.List(radixtrie.ListOptions{
+		Prefix: query.Get("prefix"),
+		After:  query.Get("after"),
+		Limit:  limit,
+	})
+
+	writeJson(w, http.StatusOK, PatternsPage{Patterns: page.Keys, Next: page.Next})
+}
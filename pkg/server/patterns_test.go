@@ -0,0 +1,47 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func Test_Patterns(t *testing.T) {
+	trie := radixtrie.NewTrie()
+	for _, p := range []string{"/about", "/catalog", "/catalog/:category", "/catalog/:category?color", "/cart"} {
+		require.Nil(t, trie.Insert(p))
+	}
+
+	srv := newTestServer(t, trie, time.Now())
+
+	t.Run("should page through patterns by prefix", func(t *testing.T) {
+		res, body := get(t, srv, "/seo/patterns", url.Values{"prefix": {"/ca"}, "limit": {"2"}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.JSONEq(t, `{"patterns": ["/cart", "/catalog"], "next": "/catalog"}`, string(body))
+
+		res, body = get(t, srv, "/seo/patterns", url.Values{"prefix": {"/ca"}, "limit": {"2"}, "after": {"/catalog"}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var page PatternsPage
+		require.Nil(t, json.Unmarshal(body, &page))
+		require.Equal(t, []string{"/catalog/:category", "/catalog/:category?color"}, page.Patterns)
+		require.Empty(t, page.Next)
+	})
+
+	t.Run("should return empty list when nothing matches", func(t *testing.T) {
+		res, body := get(t, srv, "/seo/patterns", url.Values{"prefix": {"/unknown"}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.JSONEq(t, `{"patterns": []}`, string(body))
+	})
+
+	t.Run("should return 400 for invalid limit", func(t *testing.T) {
+		for _, limit := range []string{"0", "-1", "x", "1001"} {
+			res, _ := get(t, srv, "/seo/patterns", url.Values{"limit": {limit}})
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, limit)
+		}
+	})
+}
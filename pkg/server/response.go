@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"net/http"
+)
+
+// GenerationHeader заголовок ответа с генерацией, из которой получены данные
+const GenerationHeader = "X-Seo-Generation"
+
+// Data SEO данные найденного шаблона
+type Data struct {
+	MetaTitle       *string                       `json:"metaTitle"`
+	MetaDescription *string                       `json:"metaDescription"`
+	MetaRobots      *string                       `json:"metaRobots"`
+	MetaKeywords    *string                       `json:"metaKeywords"`
+	MetaHeader      *string                       `json:"metaHeader"`
+	CanonicalLink   *string                       `json:"canonicalLink"`
+	OgTitle         *string                       `json:"ogTitle"`
+	OgDescription   *string                       `json:"ogDescription"`
+	OgImage         *string                       `json:"ogImage"`
+	OgType          *string                       `json:"ogType"`
+	Faq             []radixtrie.FaqItem           `json:"faq"`
+	TagsCloud       []radixtrie.TagsCloudItem     `json:"tagsCloud"`
+	Hreflang        []radixtrie.HreflangAlternate `json:"hreflang"`
+}
+
+// XDefault локаль альтернативной версии страницы с нейтральными данными (hreflang="x-default")
+const XDefault = "x-default"
+
+// Alternate языковая версия найденной страницы
+type Alternate struct {
+	Locale string `json:"locale"`
+	Href   string `json:"href"`
+}
+
+// Redirect редирект, который должен выполнить фронтенд вместо отрисовки страницы
+type Redirect struct {
+	Location string `json:"location"`
+	Status   int    `json:"status"`
+}
+
+// Result результат поиска url в дереве.
+// URL - нормализованный url, по которому выполнялся поиск, Site - хост или шаблон хоста сайта,
+// в дереве которого найден шаблон (пустой для сайта по умолчанию),
+// Params содержит параметры пути и значения ключей query, объявленных шаблоном,
+// CanonicalQuery - query из объявленных шаблоном ключей в каноническом порядке.
+// Locale - локаль Data, Alternates - языковые версии страницы, если у шаблона есть локализованные данные.
+// Если шаблон - источник редиректа, то заполнен Redirect, а Data пустая
+type Result struct {
+	URL            string            `json:"normalizedUrl"`
+	Site           string            `json:"site,omitempty"`
+	Pattern        string            `json:"pattern"`
+	Params         map[string]string `json:"params"`
+	CanonicalQuery string            `json:"canonicalQuery,omitempty"`
+	Locale         string            `json:"locale,omitempty"`
+	Alternates     []Alternate       `json:"alternates,omitempty"`
+	Redirect       *Redirect         `json:"redirect,omitempty"`
+	Data           *Data             `json:"data"`
+
+	// rootWildcard найденный шаблон - корневой wildcard сайта, см. Metrics
+	rootWildcard bool
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func newData(d *radixtrie.SeoData) *Data {
+	if d == nil {
+		return nil
+	}
+
+	return &Data{
+		MetaTitle:       d.MetaTitle,
+		MetaDescription: d.MetaDescription,
+		MetaRobots:      d.MetaRobots,
+		MetaKeywords:    d.MetaKeywords,
+		MetaHeader:      d.MetaHeader,
+		CanonicalLink:   d.CanonicalLink,
+		OgTitle:         d.OgTitle,
+		OgDescription:   d.OgDescription,
+		OgImage:         d.OgImage,
+		OgType:          d.OgType,
+		Faq:             d.Faq,
+		TagsCloud:       d.TagsCloud,
+		Hreflang:        d.Hreflang,
+	}
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJson(w, status, errorResponse{Error: msg})
+}
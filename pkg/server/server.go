@@ -0,0 +1,206 @@
+package server
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+	"github.com/quadgod/seo/pkg/locale"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/quadgod/seo/pkg/snapshot"
+	"github.com/quadgod/seo/pkg/urlnorm"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Server HTTP сервис поиска SEO данных по url.
+// Перед поиском url нормализуется normalizer, с которым загружаются генерации (nil - без нормализации).
+// Хост абсолютного url выбирает сайт генерации (см. site.Sites), относительные url ищутся в сайте по умолчанию.
+// Локаль данных выбирается locales по параметру locale, префиксу url и заголовку Accept-Language (nil - без префиксов)
+type Server struct {
+	holder     *snapshot.Holder
+	normalizer *urlnorm.Normalizer
+	locales    *locale.Resolver
+	logger     *slog.Logger
+
+	// fallbacks счетчики корневого wildcard текущей генерации, см. Metrics
+	fallbacks atomic.Pointer[fallbackCounter]
+}
+
+func New(holder *snapshot.Holder, normalizer *urlnorm.Normalizer, locales *locale.Resolver, logger *slog.Logger) *Server {
+	return &Server{holder: holder, normalizer: normalizer, locales: locales, logger: logger}
+}
+
+// Handler возвращает обработчик со всеми маршрутами сервиса
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /seo", s.lookup)
+	mux.HandleFunc("POST /seo/batch", s.batch)
+	mux.HandleFunc("GET /seo/patterns", s.patterns)
+	mux.HandleFunc("POST /seo/url", s.url)
+	return mux
+}
+
+// AdminHandler возвращает обработчик отладочных маршрутов: объяснение поиска и переменные expvar (/debug/vars).
+// Маршруты медленные и раскрывают внутреннее устройство сервиса, поэтому обслуживаются
+// на отдельном адресе, недоступном боевому трафику
+func (s *Server) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /seo/debug/explain", s.explain)
+	mux.Handle("GET /debug/vars", expvar.Handler())
+	return mux
+}
+
+// snapshot возвращает текущий снапшот и выставляет заголовок с его генерацией.
+// Если ни одна генерация еще не загружена, отвечает 503 и возвращает nil
+func (s *Server) snapshot(w http.ResponseWriter) *snapshot.Snapshot {
+	snap := s.holder.Load()
+	if snap == nil {
+		writeError(w, http.StatusServiceUnavailable, "generation is not loaded yet")
+		return nil
+	}
+
+	w.Header().Set(GenerationHeader, snap.Generation.UTC().Format(time.RFC3339Nano))
+	return snap
+}
+
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) {
+	host, url, err := s.normalize(r.URL.Query().Get("url"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	match, err := s.locales.Resolve(r.URL.Query().Get("locale"), r.Header.Get("Accept-Language"), url)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snap := s.snapshot(w)
+	if snap == nil {
+		return
+	}
+
+	result := s.find(snap, host, match)
+	if result == nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	writeJson(w, http.StatusOK, result)
+}
+
+// normalize отделяет хост абсолютного url запроса, нормализует и валидирует хост и url
+func (s *Server) normalize(raw string) (host string, url string, err error) {
+	if raw == "" {
+		return "", "", errors.New("url is required")
+	}
+
+	host, url = urlnorm.SplitHost(raw)
+	if host, err = site.ParseHost(host); err != nil {
+		return "", "", err
+	}
+
+	if url, err = s.normalizer.URL(url); err != nil {
+		return "", "", fmt.Errorf("invalid url: %w", err)
+	}
+
+	if err = validateURL(url); err != nil {
+		return "", "", err
+	}
+
+	return host, url, nil
+}
+
+func validateURL(url string) error {
+	if url == "" {
+		return errors.New("url is required")
+	}
+
+	if url[0] != '/' {
+		return errors.New("url must start with \"/\"")
+	}
+
+	return nil
+}
+
+// find ищет url как search и учитывает результат в метриках сервиса.
+// Используется маршрутами боевого трафика, отладочные запросы ищут через search, чтобы не искажать метрики
+func (s *Server) find(snap *snapshot.Snapshot, host string, match locale.Match) *Result {
+	result := s.search(snap.Sites, host, match)
+	if result != nil && result.rootWildcard {
+		s.countRootWildcard(snap.Generation, result.Site)
+	}
+
+	return result
+}
+
+// search ищет url без префикса локали в деревьях сайтов хоста и выбирает данные первой локали цепочки,
+// для которой они есть. Данные нейтральной локали отдаются как данные локали по умолчанию
+func (s *Server) search(sites *site.Sites, host string, match locale.Match) *Result {
+	p := radixtrie.AcquireParams()
+	defer radixtrie.ReleaseParams(p)
+
+	n, matched := sites.Search(host, match.URL, p)
+	if n == nil {
+		return nil
+	}
+
+	data, dataLocale := n.LocaleData(match.Chain)
+	if data != nil && dataLocale == locale.Neutral {
+		dataLocale = s.locales.DefaultLocale()
+	}
+
+	result := &Result{
+		URL:            match.URL,
+		Site:           matched,
+		Pattern:        n.String(),
+		Params:         p.Map(),
+		CanonicalQuery: n.CanonicalQuery(p),
+		Locale:         dataLocale,
+		Data:           newData(data.Render(p)),
+		rootWildcard:   n.IsRootWildcard(),
+	}
+	result.Alternates = s.alternates(n, result)
+
+	if n.Redirect != nil {
+		result.Redirect = &Redirect{Location: n.Redirect.Location(p), Status: n.Redirect.Status}
+	}
+
+	return result
+}
+
+// alternates языковые версии найденной страницы для hreflang: локали с данными у шаблона
+// и x-default, если у шаблона есть нейтральные данные. Для шаблонов без локализованных данных пусто
+func (s *Server) alternates(n *radixtrie.Node, result *Result) []Alternate {
+	if len(n.Localized) == 0 {
+		return nil
+	}
+
+	url, _, _ := strings.Cut(result.URL, radixtrie.QueryStart)
+	if result.CanonicalQuery != "" {
+		url += radixtrie.QueryStart + result.CanonicalQuery
+	}
+
+	locales := n.Locales()
+	if defaultLocale := s.locales.DefaultLocale(); n.Data != nil && defaultLocale != locale.Neutral && n.Localized[defaultLocale] == nil {
+		locales = append(locales, defaultLocale)
+		sort.Strings(locales)
+	}
+
+	alternates := make([]Alternate, 0, len(locales)+1)
+	for _, l := range locales {
+		alternates = append(alternates, Alternate{Locale: l, Href: s.locales.Href(l, url)})
+	}
+
+	if n.Data != nil {
+		alternates = append(alternates, Alternate{Locale: XDefault, Href: url})
+	}
+
+	return alternates
+}
@@ -0,0 +1,314 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/quadgod/seo/pkg/locale"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/quadgod/seo/pkg/snapshot"
+	"github.com/quadgod/seo/pkg/urlnorm"
+	"github.com/stretchr/testify/require"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func newTestServer(t *testing.T, trie *radixtrie.Trie, generation time.Time) *httptest.Server {
+	if trie == nil {
+		return newSitesTestServer(t, nil, generation)
+	}
+
+	return newSitesTestServer(t, site.New(trie), generation)
+}
+
+func newSitesTestServer(t *testing.T, sites *site.Sites, generation time.Time) *httptest.Server {
+	return newLocalesTestServer(t, sites, generation, nil)
+}
+
+func newLocalesTestServer(t *testing.T, sites *site.Sites, generation time.Time, locales *locale.Resolver) *httptest.Server {
+	srv := httptest.NewServer(newServer(sites, generation, locales).Handler())
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newAdminTestServer(t *testing.T, sites *site.Sites, generation time.Time) *httptest.Server {
+	srv := httptest.NewServer(newServer(sites, generation, nil).AdminHandler())
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newServer(sites *site.Sites, generation time.Time, locales *locale.Resolver) *Server {
+	holder := new(snapshot.Holder)
+	if sites != nil {
+		holder.Swap(snapshot.New(sites, generation))
+	}
+
+	return New(holder, urlnorm.New(urlnorm.DefaultOptions), locales, slog.Default())
+}
+
+func get(t *testing.T, srv *httptest.Server, path string, query url.Values) (*http.Response, []byte) {
+	res, err := http.Get(srv.URL + path + "?" + query.Encode())
+	require.Nil(t, err)
+	defer res.Body.Close()
+
+	var body json.RawMessage
+	require.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+
+	return res, body
+}
+
+func Test_Lookup(t *testing.T) {
+	generation := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	trie := radixtrie.NewTrie()
+	trie.SetCaseFolding(true)
+	trie.Insert("/catalog/:category", radixtrie.WithData(&radixtrie.SeoData{
+		MetaTitle: strPtr("category"),
+		Faq:       []radixtrie.FaqItem{{Question: "q", Answer: "a", Order: 1}},
+		TagsCloud: []radixtrie.TagsCloudItem{{Label: "Sneakers", Href: "/catalog/sneakers", Weight: 2}},
+	}))
+	restData := &radixtrie.SeoData{MetaTitle: strPtr("rest"), MetaHeader: strPtr("{{category}}: {{rest}}")}
+	require.Nil(t, restData.Compile(radixtrie.ParamKeys("/catalog/:category/*rest")))
+	trie.Insert("/catalog/:category/*rest", radixtrie.WithData(restData))
+
+	srv := newTestServer(t, trie, generation)
+
+	t.Run("should return matched pattern, params and data", func(t *testing.T) {
+		res, body := get(t, srv, "/seo", url.Values{"url": {"/catalog/shoes/red/42"}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "2025-03-20T12:00:00Z", res.Header.Get(GenerationHeader))
+
+		var result Result
+		require.Nil(t, json.Unmarshal(body, &result))
+		require.Equal(t, "/catalog/:category/*rest", result.Pattern)
+		require.Equal(t, map[string]string{"category": "shoes", "rest": "red/42"}, result.Params)
+		require.Equal(t, "rest", *result.Data.MetaTitle)
+		require.Equal(t, "shoes: red/42", *result.Data.MetaHeader)
+	})
+
+	t.Run("should return data fields as json", func(t *testing.T) {
+		res, body := get(t, srv, "/seo", url.Values{"url": {"/catalog/shoes"}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.JSONEq(t, `{
+			"normalizedUrl": "/catalog/shoes",
+			"pattern": "/catalog/:category",
+			"params": {"category": "shoes"},
+			"data": {
+				"metaTitle": "category",
+				"metaDescription": null,
+				"metaRobots": null,
+				"metaKeywords": null,
+				"metaHeader": null,
+				"canonicalLink": null,
+				"ogTitle": null,
+				"ogDescription": null,
+				"ogImage": null,
+				"ogType": null,
+				"faq": [{"question": "q", "answer": "a", "order": 1}],
+				"tagsCloud": [{"label": "Sneakers", "href": "/catalog/sneakers", "weight": 2}],
+				"hreflang": null
+			}
+		}`, string(body))
+	})
+
+	t.Run("should search normalized url", func(t *testing.T) {
+		tests := []struct {
+			raw      string
+			url      string
+			category string
+		}{
+			{"/Catalog//Shoes/", "/Catalog/Shoes", "Shoes"},
+			{"https://example.ru/catalog/./shoes#top", "/catalog/shoes", "shoes"},
+			{"/catalog/%73hoes", "/catalog/shoes", "shoes"},
+		}
+
+		for _, tt := range tests {
+			res, body := get(t, srv, "/seo", url.Values{"url": {tt.raw}})
+			require.Equal(t, http.StatusOK, res.StatusCode, tt.raw)
+
+			var result Result
+			require.Nil(t, json.Unmarshal(body, &result))
+			require.Equal(t, tt.url, result.URL, tt.raw)
+			require.Equal(t, map[string]string{"category": tt.category}, result.Params, tt.raw)
+		}
+
+		res, _ := get(t, srv, "/seo", url.Values{"url": {"/catalog/%zz"}})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should return 404 when nothing matches", func(t *testing.T) {
+		res, _ := get(t, srv, "/seo", url.Values{"url": {"/unknown"}})
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.Equal(t, "2025-03-20T12:00:00Z", res.Header.Get(GenerationHeader))
+	})
+
+	t.Run("should return 400 for invalid url", func(t *testing.T) {
+		res, _ := get(t, srv, "/seo", url.Values{})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+		res, _ = get(t, srv, "/seo", url.Values{"url": {"catalog"}})
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("should return 503 until generation is loaded", func(t *testing.T) {
+		empty := newTestServer(t, nil, time.Time{})
+		res, _ := get(t, empty, "/seo", url.Values{"url": {"/catalog/shoes"}})
+		require.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		require.Empty(t, res.Header.Get(GenerationHeader))
+	})
+}
+
+func Test_LookupQuery(t *testing.T) {
+	trie := radixtrie.NewTrie()
+	data := &radixtrie.SeoData{MetaTitle: strPtr("{{category}} {{color}}")}
+	require.Nil(t, data.Compile(radixtrie.ParamKeys("/catalog/:category?color&size?")))
+	require.Nil(t, trie.Insert("/catalog/:category?color&size?", radixtrie.WithData(data)))
+
+	srv := newTestServer(t, trie, time.Now())
+
+	res, body := get(t, srv, "/seo", url.Values{"url": {"/catalog/shoes?utm_source=mail&size=42&color=red"}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var result Result
+	require.Nil(t, json.Unmarshal(body, &result))
+	require.Equal(t, "/catalog/:category?color&size?", result.Pattern)
+	require.Equal(t, map[string]string{"category": "shoes", "color": "red", "size": "42"}, result.Params)
+	require.Equal(t, "color=red&size=42", result.CanonicalQuery)
+	require.Equal(t, "shoes red", *result.Data.MetaTitle)
+}
+
+func Test_LookupRedirect(t *testing.T) {
+	trie := radixtrie.NewTrie()
+	redirect := &radixtrie.Redirect{Target: "/catalog/{{category}}", Status: 301}
+	require.Nil(t, redirect.Compile(radixtrie.ParamKeys("/old/:category")))
+	require.Nil(t, trie.Insert("/old/:category", radixtrie.WithRedirect(redirect)))
+
+	srv := newTestServer(t, trie, time.Now())
+
+	res, body := get(t, srv, "/seo", url.Values{"url": {"/old/shoes"}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.JSONEq(t, `{
+		"normalizedUrl": "/old/shoes",
+		"pattern": "/old/:category",
+		"params": {"category": "shoes"},
+		"redirect": {"location": "/catalog/shoes", "status": 301},
+		"data": null
+	}`, string(body))
+}
+
+func Test_LookupHost(t *testing.T) {
+	sites := site.New(radixtrie.NewTrie())
+	require.Nil(t, sites.Default().Insert("/about", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: strPtr("default")})))
+	require.Nil(t, sites.Add("shop.example.ru").Insert("/about", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: strPtr("shop")})))
+	require.Nil(t, sites.Add("*.example.ru").Insert("/promo", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: strPtr("promo")})))
+
+	srv := newSitesTestServer(t, sites, time.Now())
+
+	tests := []struct {
+		url   string
+		site  string
+		title string
+	}{
+		{"/about", "", "default"},
+		{"https://Shop.Example.ru:443/about", "shop.example.ru", "shop"},
+		{"//shop.example.ru/promo", "*.example.ru", "promo"},
+		{"https://other.ru/about", "", "default"},
+	}
+
+	for _, tt := range tests {
+		res, body := get(t, srv, "/seo", url.Values{"url": {tt.url}})
+		require.Equal(t, http.StatusOK, res.StatusCode, tt.url)
+
+		var result Result
+		require.Nil(t, json.Unmarshal(body, &result))
+		require.Equal(t, tt.site, result.Site, tt.url)
+		require.Equal(t, tt.title, *result.Data.MetaTitle, tt.url)
+	}
+
+	res, _ := get(t, srv, "/seo", url.Values{"url": {"https://other.ru/promo"}})
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, _ = get(t, srv, "/seo", url.Values{"url": {"https://a..ru/about"}})
+	require.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+	res, body := get(t, srv, "/seo/patterns", url.Values{"host": {"*.example.ru"}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.JSONEq(t, `{"patterns": ["/promo"]}`, string(body))
+
+	res, _ = get(t, srv, "/seo/patterns", url.Values{"host": {"other.ru"}})
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func Test_DebugVars(t *testing.T) {
+	srv := newAdminTestServer(t, nil, time.Time{})
+
+	res, body := get(t, srv, "/debug/vars", url.Values{})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var vars map[string]json.RawMessage
+	require.Nil(t, json.Unmarshal(body, &vars))
+	require.Contains(t, vars, "memstats")
+}
+
+func Test_AdminRoutes(t *testing.T) {
+	srv := newTestServer(t, radixtrie.NewTrie(), time.Now())
+
+	for _, path := range []string{"/seo/debug/explain?url=/", "/debug/vars"} {
+		res, err := http.Get(srv.URL + path)
+		require.Nil(t, err)
+		res.Body.Close()
+		require.Equal(t, http.StatusNotFound, res.StatusCode, path)
+	}
+}
+
+func Test_LookupRootWildcardMetrics(t *testing.T) {
+	sites := site.New(radixtrie.NewTrie())
+	require.Nil(t, sites.Default().Insert("/catalog/:category"))
+	require.Nil(t, sites.Default().Insert("/*path"))
+	require.Nil(t, sites.Add("shop.example.ru").Insert("/*path"))
+
+	generation := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	s := newServer(sites, generation, nil)
+	srv := httptest.NewServer(s.Handler())
+	t.Cleanup(srv.Close)
+	admin := httptest.NewServer(s.AdminHandler())
+	t.Cleanup(admin.Close)
+
+	require.Equal(t, Metrics{RootWildcardFallbacks: map[string]uint64{}}, s.Metrics())
+
+	for _, raw := range []string{"/catalog/shoes", "/about", "https://shop.example.ru/about"} {
+		res, _ := get(t, srv, "/seo", url.Values{"url": {raw}})
+		require.Equal(t, http.StatusOK, res.StatusCode, raw)
+	}
+
+	res, _ := post(t, srv.URL+"/seo/batch", `["/catalog/shoes/red", "/catalog/shoes", "https://shop.example.ru/"]`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, _ = get(t, admin, "/seo/debug/explain", url.Values{"url": {"/about"}})
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.Equal(t, Metrics{
+		Generation:            generation,
+		RootWildcardFallbacks: map[string]uint64{"": 2, "shop.example.ru": 2},
+	}, s.Metrics())
+
+	t.Run("should reset the counters of a new generation", func(t *testing.T) {
+		next := generation.Add(time.Minute)
+		s.holder.Swap(snapshot.New(sites, next))
+
+		res, _ := get(t, srv, "/seo", url.Values{"url": {"/about"}})
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		s.countRootWildcard(generation, "")
+		require.Equal(t, Metrics{Generation: next, RootWildcardFallbacks: map[string]uint64{"": 1}}, s.Metrics())
+	})
+}
@@ -0,0 +1,70 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"net/http"
+)
+
+// maxURLBodySize ограничение размера тела запроса построения url
+const maxURLBodySize = 64 << 10
+
+// URLRequest запрос построения url по шаблону и значениям его параметров и ключей query.
+// Host - хост сайта, шаблон ищется в его деревьях так же, как url при поиске (пустой - сайт по умолчанию).
+// Pattern сравнивается с объявленным без учета ограничений параметров и порядка ключей query:
+// "/product/:id" найдет "/product/:id<int>", но имена параметров должны совпадать
+type URLRequest struct {
+	Host    string            `json:"host"`
+	Pattern string            `json:"pattern"`
+	Params  map[string]string `json:"params"`
+}
+
+// URLResponse построенный url
+type URLResponse struct {
+	URL string `json:"url"`
+}
+
+// url строит url по шаблону текущей генерации (обратный поиск) для канонических ссылок фронтенда.
+// Неизвестный шаблон - 404, отсутствующий, не подходящий под ограничение параметр или значение "." / ".." - 400
+func (s *Server) url(w http.ResponseWriter, r *http.Request) {
+	var req URLRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxURLBodySize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	if req.Pattern == "" {
+		writeError(w, http.StatusBadRequest, "pattern is required")
+		return
+	}
+
+	pattern, err := s.normalizer.Pattern(req.Pattern)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid pattern: %v", err))
+		return
+	}
+
+	host, err := site.ParseHost(req.Host)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snap := s.snapshot(w)
+	if snap == nil {
+		return
+	}
+
+	url, err := snap.Sites.URL(host, pattern, req.Params)
+	switch {
+	case errors.Is(err, radixtrie.ErrUnknownPattern):
+		writeError(w, http.StatusNotFound, err.Error())
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		writeJson(w, http.StatusOK, URLResponse{URL: url})
+	}
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_URL(t *testing.T) {
+	trie := radixtrie.NewTrie()
+	require.Nil(t, trie.Insert("/catalog/:category/*rest"))
+	require.Nil(t, trie.Insert("/product/:id<int>"))
+
+	srv := newTestServer(t, trie, time.Now())
+
+	t.Run("should build url by pattern", func(t *testing.T) {
+		res, body := post(t, srv.URL+"/seo/url", `{"pattern": "/Catalog/:category/*rest", "params": {"category": "shoes", "rest": "red/42 xl"}}`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.JSONEq(t, `{"url": "/catalog/shoes/red/42%20xl"}`, string(body))
+	})
+
+	t.Run("should build url by pattern of host site with fallback to default", func(t *testing.T) {
+		res, body := post(t, srv.URL+"/seo/url", `{"host": "shop.example.ru", "pattern": "/product/:id<int>", "params": {"id": "42"}}`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.JSONEq(t, `{"url": "/product/42"}`, string(body))
+	})
+
+	t.Run("should return 404 for unknown pattern", func(t *testing.T) {
+		res, _ := post(t, srv.URL+"/seo/url", `{"pattern": "/unknown", "params": {}}`)
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("should return 400 for invalid params", func(t *testing.T) {
+		for _, body := range []string{
+			`{"pattern": "/catalog/:category/*rest", "params": {"category": "shoes"}}`,
+			`{"pattern": "/product/:id<int>", "params": {"id": "x"}}`,
+			`{"params": {}}`,
+			`[]`,
+		} {
+			res, _ := post(t, srv.URL+"/seo/url", body)
+			require.Equal(t, http.StatusBadRequest, res.StatusCode, body)
+		}
+	})
+}
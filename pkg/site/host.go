@@ -0,0 +1,70 @@
+package site
+
+import (
+	"fmt"
+	"strings"
+)
+
+// wildcardPrefix начало шаблона хоста, под который попадают все его поддомены: *.example.ru
+const wildcardPrefix = "*."
+
+// ParseHost приводит хост или шаблон хоста к виду, в котором он хранится в Sites:
+// нижний регистр, без порта, userinfo и завершающей точки. Пустой хост - сайт по умолчанию (Default)
+func ParseHost(raw string) (string, error) {
+	host := raw
+	if i := strings.LastIndexByte(host, '@'); i != -1 {
+		host = host[i+1:]
+	}
+
+	if i := strings.LastIndexByte(host, ':'); i != -1 && isPort(host[i+1:]) {
+		host = host[:i]
+	}
+
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == Default {
+		return Default, nil
+	}
+
+	labels := strings.Split(host, ".")
+	for i, label := range labels {
+		if label == "*" && i == 0 && len(labels) > 1 {
+			continue
+		}
+
+		if label == "" || strings.ContainsAny(label, "*/?#@:[] ") {
+			return "", fmt.Errorf("invalid host %q", raw)
+		}
+	}
+
+	return host, nil
+}
+
+// IsPattern сообщает, является ли хост шаблоном поддоменов
+func IsPattern(host string) bool {
+	return strings.HasPrefix(host, wildcardPrefix)
+}
+
+// Match сообщает, подходит ли хост под шаблон: *.example.ru подходит для a.example.ru и a.b.example.ru,
+// но не для самого example.ru
+func Match(pattern, host string) bool {
+	if !IsPattern(pattern) {
+		return pattern == host
+	}
+
+	suffix := pattern[len(wildcardPrefix)-1:]
+	return len(host) > len(suffix) && strings.HasSuffix(host, suffix)
+}
+
+func isPort(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
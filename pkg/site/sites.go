@@ -0,0 +1,118 @@
+package site
+
+import (
+	"errors"
+	"fmt"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"iter"
+	"sort"
+)
+
+// Default хост сайта по умолчанию, на который приходятся декларации без хоста
+// и поиск url, не найденных в деревьях хоста
+const Default = ""
+
+// Sites деревья сайтов одной генерации: по дереву на хост или шаблон хоста (*.example.ru) и дерево сайта по умолчанию.
+// Url ищется сначала в дереве хоста, затем в деревьях подходящих шаблонов, от более конкретного к общему,
+// и в конце в дереве сайта по умолчанию.
+// Sites заполняется при загрузке генерации, после публикации не модифицируется
+type Sites struct {
+	tries map[string]*radixtrie.Trie
+	// patterns шаблоны хостов, отсортированные от более длинного суффикса к более короткому
+	patterns []string
+}
+
+// New создает Sites с деревом сайта по умолчанию
+func New(defaultTrie *radixtrie.Trie) *Sites {
+	return &Sites{tries: map[string]*radixtrie.Trie{Default: defaultTrie}}
+}
+
+// Set задает дерево хоста, хост должен быть приведен ParseHost
+func (s *Sites) Set(host string, trie *radixtrie.Trie) {
+	if _, ok := s.tries[host]; !ok && IsPattern(host) {
+		s.patterns = append(s.patterns, host)
+		sort.Slice(s.patterns, func(i, j int) bool {
+			if len(s.patterns[i]) != len(s.patterns[j]) {
+				return len(s.patterns[i]) > len(s.patterns[j])
+			}
+			return s.patterns[i] < s.patterns[j]
+		})
+	}
+
+	s.tries[host] = trie
+}
+
+// Add возвращает дерево хоста, создавая пустое, если его еще нет
+func (s *Sites) Add(host string) *radixtrie.Trie {
+	trie := s.tries[host]
+	if trie == nil {
+		trie = radixtrie.NewTrie()
+		s.Set(host, trie)
+	}
+
+	return trie
+}
+
+// Trie возвращает дерево, объявленное ровно для этого хоста или шаблона, или nil
+func (s *Sites) Trie(host string) *radixtrie.Trie {
+	return s.tries[host]
+}
+
+// Default возвращает дерево сайта по умолчанию
+func (s *Sites) Default() *radixtrie.Trie {
+	return s.tries[Default]
+}
+
+// Hosts возвращает отсортированные хосты и шаблоны всех сайтов, первым идет Default
+func (s *Sites) Hosts() []string {
+	hosts := make([]string, 0, len(s.tries))
+	for host := range s.tries {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	return hosts
+}
+
+// Resolve перебирает деревья, в которых ищутся url хоста, в порядке поиска
+func (s *Sites) Resolve(host string) iter.Seq2[string, *radixtrie.Trie] {
+	return func(yield func(string, *radixtrie.Trie) bool) {
+		if host != Default {
+			if trie := s.tries[host]; trie != nil && !yield(host, trie) {
+				return
+			}
+
+			for _, pattern := range s.patterns {
+				if pattern != host && Match(pattern, host) && !yield(pattern, s.tries[pattern]) {
+					return
+				}
+			}
+		}
+
+		yield(Default, s.tries[Default])
+	}
+}
+
+// Search ищет url в деревьях хоста (см. Resolve) и возвращает найденный узел и хост сайта, которому он принадлежит
+func (s *Sites) Search(host, url string, params radixtrie.ParamsSetter) (*radixtrie.Node, string) {
+	for site, trie := range s.Resolve(host) {
+		if n := trie.Search(url, params); n != nil {
+			return n, site
+		}
+	}
+
+	return nil, Default
+}
+
+// URL строит url по шаблону первого из деревьев хоста (см. Resolve), в котором он объявлен.
+// Если шаблон не объявлен ни в одном, возвращает radixtrie.ErrUnknownPattern
+func (s *Sites) URL(host, pattern string, params map[string]string) (string, error) {
+	for _, trie := range s.Resolve(host) {
+		url, err := trie.URL(pattern, params)
+		if !errors.Is(err, radixtrie.ErrUnknownPattern) {
+			return url, err
+		}
+	}
+
+	return "", fmt.Errorf("%w %q", radixtrie.ErrUnknownPattern, pattern)
+}
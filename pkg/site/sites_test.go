@@ -0,0 +1,93 @@
+package site
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_ParseHost(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+	}{
+		{"", Default},
+		{"Shop.Example.ru", "shop.example.ru"},
+		{"shop.example.ru:8443", "shop.example.ru"},
+		{"user@shop.example.ru.", "shop.example.ru"},
+		{"*.Example.ru", "*.example.ru"},
+		{"магазин.рф", "магазин.рф"},
+	}
+
+	for _, tt := range tests {
+		actual, err := ParseHost(tt.raw)
+		require.Nil(t, err, tt.raw)
+		require.Equal(t, tt.expected, actual, tt.raw)
+	}
+
+	for _, raw := range []string{"*", "a.*.ru", "*example.ru", "a..ru", "a/b", "a b.ru"} {
+		_, err := ParseHost(raw)
+		require.NotNil(t, err, raw)
+	}
+}
+
+func Test_Sites(t *testing.T) {
+	insert := func(trie *radixtrie.Trie, patterns ...string) {
+		for _, p := range patterns {
+			require.Nil(t, trie.Insert(p))
+		}
+	}
+
+	sites := New(radixtrie.NewTrie())
+	insert(sites.Default(), "/", "/about", "/catalog/:category")
+	insert(sites.Add("shop.example.ru"), "/catalog/:category", "/promo")
+	insert(sites.Add("*.example.ru"), "/about")
+	insert(sites.Add("*.spb.example.ru"), "/about", "/contacts")
+
+	t.Run("should search host, host patterns and default site in order", func(t *testing.T) {
+		tests := []struct {
+			host, url string
+			site      string
+			pattern   string
+		}{
+			{"shop.example.ru", "/catalog/shoes", "shop.example.ru", "/catalog/:category"},
+			{"shop.example.ru", "/about", "*.example.ru", "/about"},
+			{"shop.example.ru", "/", Default, "/"},
+			{"a.spb.example.ru", "/about", "*.spb.example.ru", "/about"},
+			{"a.msk.example.ru", "/about", "*.example.ru", "/about"},
+			{"example.ru", "/about", Default, "/about"},
+			{Default, "/catalog/shoes", Default, "/catalog/:category"},
+			{"other.ru", "/promo", Default, ""},
+		}
+
+		for _, tt := range tests {
+			n, site := sites.Search(tt.host, tt.url, new(radixtrie.Params))
+			if tt.pattern == "" {
+				require.Nil(t, n, tt.host+tt.url)
+				continue
+			}
+
+			require.NotNil(t, n, tt.host+tt.url)
+			require.Equal(t, tt.pattern, n.String(), tt.host+tt.url)
+			require.Equal(t, tt.site, site, tt.host+tt.url)
+		}
+	})
+
+	t.Run("should build url by pattern of host or default site", func(t *testing.T) {
+		url, err := sites.URL("shop.example.ru", "/promo", nil)
+		require.Nil(t, err)
+		require.Equal(t, "/promo", url)
+
+		url, err = sites.URL("a.spb.example.ru", "/catalog/:category", map[string]string{"category": "shoes"})
+		require.Nil(t, err)
+		require.Equal(t, "/catalog/shoes", url)
+
+		_, err = sites.URL("other.ru", "/promo", nil)
+		require.ErrorIs(t, err, radixtrie.ErrUnknownPattern)
+	})
+
+	t.Run("should list hosts", func(t *testing.T) {
+		require.Equal(t, []string{Default, "*.example.ru", "*.spb.example.ru", "shop.example.ru"}, sites.Hosts())
+		require.Nil(t, sites.Trie("unknown.ru"))
+	})
+}
@@ -0,0 +1,244 @@
+package snapshot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	fileExt = ".trie"
+	// fileVersion версия формата файла, файлы других версий не читаются
+	fileVersion uint16 = 3
+)
+
+// ErrFingerprintMismatch снапшот построен с другими настройками, его нужно построить заново
+var ErrFingerprintMismatch = errors.New("snapshot fingerprint mismatch")
+
+// FileName имя файла снапшота генерации
+func FileName(generation time.Time) string {
+	return fmt.Sprintf("%d%s", generation.UnixMicro(), fileExt)
+}
+
+// WriteFile сохраняет снапшот в директорию dir вместе с отпечатком fingerprint настроек, с которыми построены деревья.
+// Файл сначала пишется во временный и затем переименовывается, поэтому читатели никогда не видят недописанный снапшот.
+// Формат файла: генерация (unix микросекунды, int64 big endian) | версия формата (uint16) |
+// длина отпечатка (uint16) | отпечаток | количество сайтов (uint32) |
+// для каждого сайта: длина хоста (uint16) | хост | длина дерева (uint32) | radixtrie.Trie.MarshalBinary
+func WriteFile(dir string, s *Snapshot, fingerprint string) (err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, os.Remove(tmp.Name()))
+		}
+	}()
+
+	w := bufio.NewWriter(tmp)
+	if err = binary.Write(w, binary.BigEndian, s.Generation.UnixMicro()); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+
+	if err = writeHeader(w, fingerprint); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+
+	if err = writeSites(w, s.Sites); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+
+	if err = w.Flush(); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return errors.Join(err, tmp.Close())
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path.Join(dir, FileName(s.Generation)))
+}
+
+// ReadFile читает снапшот генерации из директории dir.
+// Если файла нет, возвращает ошибку, для которой errors.Is(err, os.ErrNotExist),
+// если снапшот сохранен с другим отпечатком настроек - ErrFingerprintMismatch
+func ReadFile(dir string, generation time.Time, fingerprint string) (*Snapshot, error) {
+	f, err := os.Open(path.Join(dir, FileName(generation)))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+
+	var micro int64
+	if err = binary.Read(r, binary.BigEndian, &micro); err != nil {
+		return nil, fmt.Errorf("read snapshot generation errors: %w", err)
+	}
+
+	if micro != generation.UnixMicro() {
+		return nil, fmt.Errorf("snapshot file contains generation %d instead of %d", micro, generation.UnixMicro())
+	}
+
+	if err = readHeader(r, fingerprint); err != nil {
+		return nil, err
+	}
+
+	sites, err := readSites(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(sites, generation), nil
+}
+
+func writeHeader(w io.Writer, fingerprint string) error {
+	if err := binary.Write(w, binary.BigEndian, fileVersion); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, binary.BigEndian, uint16(len(fingerprint))); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, fingerprint)
+	return err
+}
+
+func readHeader(r io.Reader, fingerprint string) error {
+	var version uint16
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return fmt.Errorf("read snapshot version errors: %w", err)
+	}
+
+	if version != fileVersion {
+		return fmt.Errorf("unsupported snapshot version %d", version)
+	}
+
+	var fingerprintLen uint16
+	if err := binary.Read(r, binary.BigEndian, &fingerprintLen); err != nil {
+		return fmt.Errorf("read snapshot fingerprint errors: %w", err)
+	}
+
+	written := make([]byte, fingerprintLen)
+	if _, err := io.ReadFull(r, written); err != nil {
+		return fmt.Errorf("read snapshot fingerprint errors: %w", err)
+	}
+
+	if string(written) != fingerprint {
+		return ErrFingerprintMismatch
+	}
+
+	return nil
+}
+
+func writeSites(w io.Writer, sites *site.Sites) error {
+	hosts := sites.Hosts()
+	if err := binary.Write(w, binary.BigEndian, uint32(len(hosts))); err != nil {
+		return err
+	}
+
+	for _, host := range hosts {
+		data, err := sites.Trie(host).MarshalBinary()
+		if err != nil {
+			return err
+		}
+
+		if err = binary.Write(w, binary.BigEndian, uint16(len(host))); err != nil {
+			return err
+		}
+
+		if _, err = io.WriteString(w, host); err != nil {
+			return err
+		}
+
+		if err = binary.Write(w, binary.BigEndian, uint32(len(data))); err != nil {
+			return err
+		}
+
+		if _, err = w.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readSites(r io.Reader) (*site.Sites, error) {
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, fmt.Errorf("read snapshot sites errors: %w", err)
+	}
+
+	sites := site.New(radixtrie.NewTrie())
+	for i := uint32(0); i < count; i++ {
+		var hostLen uint16
+		if err := binary.Read(r, binary.BigEndian, &hostLen); err != nil {
+			return nil, fmt.Errorf("read snapshot site errors: %w", err)
+		}
+
+		host := make([]byte, hostLen)
+		if _, err := io.ReadFull(r, host); err != nil {
+			return nil, fmt.Errorf("read snapshot site errors: %w", err)
+		}
+
+		var trieLen uint32
+		if err := binary.Read(r, binary.BigEndian, &trieLen); err != nil {
+			return nil, fmt.Errorf("read snapshot site %q errors: %w", host, err)
+		}
+
+		data := make([]byte, trieLen)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("read snapshot site %q errors: %w", host, err)
+		}
+
+		trie := radixtrie.NewTrie()
+		if err := trie.UnmarshalBinary(data); err != nil {
+			return nil, fmt.Errorf("read snapshot site %q trie errors: %w", host, err)
+		}
+		sites.Set(string(host), trie)
+	}
+
+	if _, err := r.Read(make([]byte, 1)); err != io.EOF {
+		return nil, errors.New("unexpected trailing bytes in snapshot file")
+	}
+
+	return sites, nil
+}
+
+// RemoveStale удаляет из директории dir снапшоты всех генераций, кроме указанной
+func RemoveStale(dir string, keep time.Time) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var errs error
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, fileExt) || name == FileName(keep) {
+			continue
+		}
+
+		errs = errors.Join(errs, os.Remove(path.Join(dir, name)))
+	}
+
+	return errs
+}
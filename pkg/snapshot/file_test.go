@@ -0,0 +1,82 @@
+package snapshot
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func Test_File(t *testing.T) {
+	dir := path.Join(t.TempDir(), "/snapshots")
+	generation := time.Date(2025, 3, 20, 12, 0, 0, 123456000, time.UTC)
+
+	title := "main"
+	trie := radixtrie.NewTrie()
+	require.Nil(t, trie.Insert("/", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: &title})))
+	require.Nil(t, trie.Insert("/catalog/:category"))
+
+	sites := site.New(trie)
+	require.Nil(t, sites.Add("*.example.ru").Insert("/promo"))
+
+	t.Run("should write and read snapshot", func(t *testing.T) {
+		require.Nil(t, WriteFile(dir, New(sites, generation), "v1"))
+
+		s, err := ReadFile(dir, generation, "v1")
+		require.Nil(t, err)
+		require.True(t, generation.Equal(s.Generation))
+		require.Equal(t, "main", *s.Sites.Default().Search("/", new(radixtrie.Params)).Data.MetaTitle)
+		require.Equal(t, "/catalog/:category", s.Sites.Default().Search("/catalog/shoes", new(radixtrie.Params)).String())
+		require.Equal(t, []string{site.Default, "*.example.ru"}, s.Sites.Hosts())
+
+		n, host := s.Sites.Search("shop.example.ru", "/promo", new(radixtrie.Params))
+		require.Equal(t, "/promo", n.String())
+		require.Equal(t, "*.example.ru", host)
+
+		entries, err := os.ReadDir(dir)
+		require.Nil(t, err)
+		require.Len(t, entries, 1, "temporary file must be renamed")
+	})
+
+	t.Run("should return not exist error for unknown generation", func(t *testing.T) {
+		_, err := ReadFile(dir, generation.Add(time.Hour), "v1")
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("should reject snapshot of other settings", func(t *testing.T) {
+		_, err := ReadFile(dir, generation, "v2")
+		require.ErrorIs(t, err, ErrFingerprintMismatch)
+	})
+
+	t.Run("should reject corrupted file", func(t *testing.T) {
+		name := path.Join(dir, FileName(generation))
+		data, err := os.ReadFile(name)
+		require.Nil(t, err)
+
+		data[len(data)-1] ^= 0xff
+		require.Nil(t, os.WriteFile(name, data, 0644))
+
+		_, err = ReadFile(dir, generation, "v1")
+		require.ErrorIs(t, err, radixtrie.ErrInvalidBinary)
+	})
+
+	t.Run("should remove snapshots of other generations", func(t *testing.T) {
+		next := generation.Add(time.Hour)
+		require.Nil(t, WriteFile(dir, New(sites, generation), "v1"))
+		require.Nil(t, WriteFile(dir, New(sites, next), "v1"))
+		require.Nil(t, os.WriteFile(path.Join(dir, "readme.txt"), nil, 0644))
+
+		require.Nil(t, RemoveStale(dir, next))
+
+		entries, err := os.ReadDir(dir)
+		require.Nil(t, err)
+		names := make([]string, 0)
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		require.ElementsMatch(t, []string{FileName(next), "readme.txt"}, names)
+	})
+}
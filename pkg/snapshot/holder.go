@@ -0,0 +1,74 @@
+package snapshot
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Holder хранит текущий снапшот. Читатели получают снапшот один раз на запрос через Load
+// и работают с ним до конца запроса, даже если в это время произошла подмена.
+// Holder не держит ссылок на предыдущие снапшоты, поэтому после подмены старое дерево
+// освобождается сборщиком мусора, как только его перестанут использовать текущие запросы
+type Holder struct {
+	current atomic.Pointer[Snapshot]
+
+	swaps              atomic.Uint64
+	lastSwapAt         atomic.Int64
+	lastPublishLatency atomic.Int64
+	maxPublishLatency  atomic.Int64
+}
+
+// Metrics метрики подмены снапшотов.
+// PublishLatency - время от начала загрузки генерации (Snapshot.StartedAt) до ее публикации,
+// то есть сколько под отдавал старые данные после того, как начал грузить новые.
+// Снапшоты без StartedAt в задержках не учитываются
+type Metrics struct {
+	Swaps              uint64        `json:"swaps"`
+	LastSwapAt         time.Time     `json:"lastSwapAt"`
+	LastPublishLatency time.Duration `json:"lastPublishLatency"`
+	MaxPublishLatency  time.Duration `json:"maxPublishLatency"`
+}
+
+// Load возвращает текущий снапшот или nil, если ни одна генерация еще не загружена
+func (h *Holder) Load() *Snapshot {
+	return h.current.Load()
+}
+
+// Swap атомарно публикует новый снапшот и возвращает предыдущий
+func (h *Holder) Swap(s *Snapshot) *Snapshot {
+	prev := h.current.Swap(s)
+	swappedAt := time.Now()
+
+	h.swaps.Add(1)
+	h.lastSwapAt.Store(swappedAt.UnixNano())
+
+	if s.StartedAt.IsZero() {
+		return prev
+	}
+
+	latency := int64(swappedAt.Sub(s.StartedAt))
+	h.lastPublishLatency.Store(latency)
+	for {
+		maxLatency := h.maxPublishLatency.Load()
+		if latency <= maxLatency || h.maxPublishLatency.CompareAndSwap(maxLatency, latency) {
+			break
+		}
+	}
+
+	return prev
+}
+
+// Metrics возвращает метрики подмены снапшотов
+func (h *Holder) Metrics() Metrics {
+	m := Metrics{
+		Swaps:              h.swaps.Load(),
+		LastPublishLatency: time.Duration(h.lastPublishLatency.Load()),
+		MaxPublishLatency:  time.Duration(h.maxPublishLatency.Load()),
+	}
+
+	if lastSwapAt := h.lastSwapAt.Load(); lastSwapAt != 0 {
+		m.LastSwapAt = time.Unix(0, lastSwapAt)
+	}
+
+	return m
+}
@@ -0,0 +1,95 @@
+package snapshot
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/quadgod/seo/pkg/site"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_Holder(t *testing.T) {
+	t.Run("should return nil before first swap", func(t *testing.T) {
+		h := new(Holder)
+		require.Nil(t, h.Load())
+		require.Equal(t, uint64(0), h.Metrics().Swaps)
+		require.True(t, h.Metrics().LastSwapAt.IsZero())
+	})
+
+	t.Run("should swap snapshots and return previous one", func(t *testing.T) {
+		h := new(Holder)
+		first := New(site.New(radixtrie.NewTrie()), time.Unix(1, 0))
+		second := New(site.New(radixtrie.NewTrie()), time.Unix(2, 0))
+
+		require.Nil(t, h.Swap(first))
+		require.Same(t, first, h.Load())
+
+		require.Same(t, first, h.Swap(second))
+		require.Same(t, second, h.Load())
+
+		m := h.Metrics()
+		require.Equal(t, uint64(2), m.Swaps)
+		require.False(t, m.LastSwapAt.IsZero())
+		require.Zero(t, m.LastPublishLatency)
+	})
+
+	t.Run("should measure latency from load start to publish", func(t *testing.T) {
+		h := new(Holder)
+		slow := New(site.New(radixtrie.NewTrie()), time.Unix(1, 0))
+		slow.StartedAt = time.Now().Add(-time.Minute)
+		fast := New(site.New(radixtrie.NewTrie()), time.Unix(2, 0))
+		fast.StartedAt = time.Now().Add(-time.Second)
+
+		h.Swap(slow)
+		h.Swap(fast)
+
+		m := h.Metrics()
+		require.GreaterOrEqual(t, m.LastPublishLatency, time.Second)
+		require.Less(t, m.LastPublishLatency, time.Minute)
+		require.GreaterOrEqual(t, m.MaxPublishLatency, time.Minute)
+	})
+
+	t.Run("should serve readers consistent snapshots while swapping", func(t *testing.T) {
+		h := new(Holder)
+		build := func(generation int64) *Snapshot {
+			trie := radixtrie.NewTrie()
+			title := time.Unix(generation, 0).UTC().Format(time.RFC3339)
+			trie.Insert("/page", radixtrie.WithData(&radixtrie.SeoData{MetaTitle: &title}))
+			return New(site.New(trie), time.Unix(generation, 0))
+		}
+		h.Swap(build(0))
+
+		var wg sync.WaitGroup
+		stop := make(chan struct{})
+
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+
+					s := h.Load()
+					n := s.Sites.Default().Search("/page", new(radixtrie.Params))
+					if n == nil || *n.Data.MetaTitle != s.Generation.UTC().Format(time.RFC3339) {
+						t.Errorf("snapshot is inconsistent")
+						return
+					}
+				}
+			}()
+		}
+
+		for generation := int64(1); generation <= 100; generation++ {
+			h.Swap(build(generation))
+		}
+		close(stop)
+		wg.Wait()
+
+		require.Equal(t, uint64(101), h.Metrics().Swaps)
+	})
+}
@@ -0,0 +1,26 @@
+package snapshot
+
+import (
+	"github.com/quadgod/seo/pkg/site"
+	"time"
+)
+
+// Snapshot неизменяемый срез данных одной генерации. После публикации через Holder
+// деревья сайтов снапшота не модифицируются, поэтому читать их можно без блокировок
+type Snapshot struct {
+	Sites      *site.Sites
+	Generation time.Time
+	LoadedAt   time.Time
+	// StartedAt время начала загрузки генерации (чтения из базы или файла снапшота), выставляется загрузчиком.
+	// По нему Holder считает задержку публикации, нулевое значение - задержка неизвестна
+	StartedAt time.Time
+}
+
+// New создает снапшот генерации из полностью построенных деревьев сайтов
+func New(sites *site.Sites, generation time.Time) *Snapshot {
+	return &Snapshot{
+		Sites:      sites,
+		Generation: generation,
+		LoadedAt:   time.Now(),
+	}
+}
@@ -0,0 +1,299 @@
+package urlnorm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidEscape ошибка разбора percent-encoding последовательности
+	ErrInvalidEscape = errors.New("invalid percent-encoding")
+	// ErrInvalidUTF8 путь после percent-декодирования не является валидным UTF-8
+	ErrInvalidUTF8 = errors.New("invalid utf-8")
+)
+
+// TrailingSlash политика завершающего слеша пути
+type TrailingSlash int
+
+const (
+	// TrailingSlashKeep оставляет завершающий слеш как есть
+	TrailingSlashKeep TrailingSlash = iota
+	// TrailingSlashStrip удаляет завершающий слеш
+	TrailingSlashStrip
+	// TrailingSlashAdd добавляет завершающий слеш, кроме путей, заканчивающихся wildcard параметром
+	TrailingSlashAdd
+)
+
+// Options шаги нормализации
+type Options struct {
+	// Lowercase приводит статические сегменты шаблонов к нижнему регистру. Url не изменяются:
+	// дерево сравнивает с шаблонами их статические сегменты без учета регистра (см. radixtrie.Trie.SetCaseFolding),
+	// а значения параметров (:name, *name) и их ограничения видят url как есть
+	Lowercase bool
+	// CollapseSlashes схлопывает повторяющиеся слеши
+	CollapseSlashes bool
+	// RemoveDotSegments удаляет сегменты "." и ".." (RFC 3986, 5.2.4)
+	RemoveDotSegments bool
+	// DecodePercent декодирует percent-encoding пути, кроме "/", "?", "#", "%" и управляющих символов,
+	// результат должен быть валидным UTF-8
+	DecodePercent bool
+	// StripHost отрезает схему и хост у абсолютных url
+	StripHost bool
+	// TrailingSlash политика завершающего слеша
+	TrailingSlash TrailingSlash
+}
+
+// DefaultOptions включает все шаги и удаляет завершающий слеш
+var DefaultOptions = Options{
+	Lowercase:         true,
+	CollapseSlashes:   true,
+	RemoveDotSegments: true,
+	DecodePercent:     true,
+	StripHost:         true,
+	TrailingSlash:     TrailingSlashStrip,
+}
+
+// Normalizer приводит url и шаблоны к одному виду, чтобы поиск по дереву не зависел от их записи.
+// Одни и те же настройки должны применяться и при вставке шаблонов (Pattern), и при поиске (URL).
+// Методы nil Normalizer возвращают значения без изменений
+type Normalizer struct {
+	opts Options
+}
+
+func New(opts Options) *Normalizer {
+	return &Normalizer{opts: opts}
+}
+
+// Options возвращает настройки нормализатора
+func (n *Normalizer) Options() Options {
+	if n == nil {
+		return Options{}
+	}
+	return n.opts
+}
+
+// URL нормализует url запроса: путь обрабатывается включенными шагами,
+// query сохраняется как есть, фрагмент отбрасывается
+func (n *Normalizer) URL(raw string) (string, error) {
+	if n == nil {
+		return raw, nil
+	}
+
+	return n.normalize(raw, false)
+}
+
+// Pattern нормализует шаблон так же, как URL нормализует url, но сегменты с параметрами
+// (:name, :name<spec>, *name) и query шаблона не изменяются
+func (n *Normalizer) Pattern(pattern string) (string, error) {
+	if n == nil {
+		return pattern, nil
+	}
+
+	return n.normalize(pattern, true)
+}
+
+func (n *Normalizer) normalize(raw string, isPattern bool) (string, error) {
+	if !isPattern {
+		if i := strings.IndexByte(raw, '#'); i != -1 {
+			raw = raw[:i]
+		}
+	}
+
+	if n.opts.StripHost {
+		raw = stripHost(raw)
+	}
+
+	path, query, hasQuery := strings.Cut(raw, "?")
+	if path == "" || path[0] != '/' {
+		// относительные url не нормализуются, их отклоняет валидация
+		return raw, nil
+	}
+
+	path, err := n.path(path, isPattern)
+	if err != nil {
+		return "", err
+	}
+
+	if hasQuery && query != "" {
+		return path + "?" + query, nil
+	}
+
+	return path, nil
+}
+
+func (n *Normalizer) path(path string, isPattern bool) (string, error) {
+	if !utf8.ValidString(path) {
+		return "", ErrInvalidUTF8
+	}
+
+	raw := strings.Split(path[1:], "/")
+	segments := make([]string, 0, len(raw))
+	trailing := false
+
+	for i, s := range raw {
+		last := i == len(raw)-1
+		trailing = last && s == ""
+
+		if isPattern && isDynamic(s) {
+			segments = append(segments, s)
+			continue
+		}
+
+		if n.opts.DecodePercent {
+			decoded, err := decode(s)
+			if err != nil {
+				return "", fmt.Errorf("segment %q: %w", s, err)
+			}
+			s = decoded
+		}
+
+		if isPattern && n.opts.Lowercase {
+			s = strings.ToLower(s)
+		}
+
+		switch {
+		case s == "" && (last || n.opts.CollapseSlashes):
+			continue
+		case n.opts.RemoveDotSegments && s == ".":
+			trailing = last
+			continue
+		case n.opts.RemoveDotSegments && s == "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+			trailing = last
+			continue
+		}
+
+		segments = append(segments, s)
+	}
+
+	switch n.opts.TrailingSlash {
+	case TrailingSlashStrip:
+		trailing = false
+	case TrailingSlashAdd:
+		trailing = len(segments) == 0 || !isPattern || !strings.HasPrefix(segments[len(segments)-1], "*")
+	}
+
+	if len(segments) == 0 {
+		return "/", nil
+	}
+
+	path = "/" + strings.Join(segments, "/")
+	if trailing {
+		path += "/"
+	}
+
+	return path, nil
+}
+
+func isDynamic(s string) bool {
+	return s != "" && (s[0] == ':' || s[0] == '*')
+}
+
+// stripHost отрезает схему и хост (scheme://host или //host) у абсолютного url
+func stripHost(raw string) string {
+	_, rest := SplitHost(raw)
+	return rest
+}
+
+// SplitHost разделяет абсолютный url (scheme://host/path или //host/path) на хост (как есть, с портом)
+// и путь с query. Для относительного url хост пустой, а url возвращается без изменений
+func SplitHost(raw string) (host string, rest string) {
+	var ok bool
+	if strings.HasPrefix(raw, "//") {
+		rest, ok = raw[2:], true
+	} else if i := strings.Index(raw, "://"); i > 0 && isScheme(raw[:i]) {
+		rest, ok = raw[i+3:], true
+	}
+
+	if !ok {
+		return "", raw
+	}
+
+	if i := strings.IndexAny(rest, "/?#"); i != -1 {
+		host = rest[:i]
+		switch rest[i] {
+		case '?', '#':
+			return host, "/" + rest[i:]
+		}
+		return host, rest[i:]
+	}
+
+	return rest, "/"
+}
+
+func isScheme(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+
+	return s != ""
+}
+
+const upperHex = "0123456789ABCDEF"
+
+// decode декодирует percent-encoding сегмента. Символы, которые изменили бы разбор url,
+// и управляющие символы остаются закодированными (в верхнем регистре)
+func decode(s string) (string, error) {
+	if strings.IndexByte(s, '%') == -1 {
+		return s, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+
+		if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			return "", ErrInvalidEscape
+		}
+
+		v := unhex(s[i+1])<<4 | unhex(s[i+2])
+		i += 2
+
+		switch {
+		case v == '/' || v == '?' || v == '#' || v == '%' || v < 0x20 || v == 0x7f:
+			b.WriteByte('%')
+			b.WriteByte(upperHex[v>>4])
+			b.WriteByte(upperHex[v&0xf])
+		default:
+			b.WriteByte(v)
+		}
+	}
+
+	decoded := b.String()
+	if !utf8.ValidString(decoded) {
+		return "", ErrInvalidUTF8
+	}
+
+	return decoded, nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,220 @@
+package urlnorm
+
+import (
+	"github.com/quadgod/seo/pkg/radixtrie"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_URL(t *testing.T) {
+	n := New(DefaultOptions)
+
+	tests := []struct {
+		raw      string
+		expected string
+	}{
+		{"/", "/"},
+		{"", ""},
+		{"/Catalog//Shoes/", "/Catalog/Shoes"},
+		{"/catalog/shoes?Color=Red#top", "/catalog/shoes?Color=Red"},
+		{"/catalog/shoes?", "/catalog/shoes"},
+		{"/%D0%9E%D0%B1%D1%83%D0%B2%D1%8C", "/Обувь"},
+		{"/%d0%be%d0%b1%d1%83%d0%b2%d1%8c", "/обувь"},
+		{"/Обувь", "/Обувь"},
+		{"/a%2Fb/c%3fd/e%25f/%7e", "/a%2Fb/c%3Fd/e%25f/~"},
+		{"/a/./b/../c/", "/a/c"},
+		{"/../a", "/a"},
+		{"/a/%2E%2E/b", "/b"},
+		{"https://Example.ru/Catalog?x=1", "/Catalog?x=1"},
+		{"http://example.ru", "/"},
+		{"//example.ru/a", "/a"},
+		{"https://example.ru?x=1", "/?x=1"},
+		{"catalog", "catalog"},
+	}
+
+	for _, tt := range tests {
+		actual, err := n.URL(tt.raw)
+		require.Nil(t, err, tt.raw)
+		require.Equal(t, tt.expected, actual, tt.raw)
+	}
+
+	for _, raw := range []string{"/a%", "/a%2", "/a%zz", "/%D0", "/%FF", "/\xff"} {
+		_, err := n.URL(raw)
+		require.NotNil(t, err, raw)
+	}
+}
+
+func Test_Pattern(t *testing.T) {
+	n := New(DefaultOptions)
+
+	tests := []struct {
+		raw      string
+		expected string
+	}{
+		{"/Catalog/:categoryId/*Rest", "/catalog/:categoryId/*Rest"},
+		{"/Catalog/:id<[A-Z]+>/", "/catalog/:id<[A-Z]+>"},
+		{"/Brands?Letter&sort?", "/brands?Letter&sort?"},
+		{"https://example.ru/%D0%9E%D0%B1%D1%83%D0%B2%D1%8C", "/обувь"},
+		{"/A%2fB/%3F", "/a%2fb/%3f"},
+	}
+
+	for _, tt := range tests {
+		actual, err := n.Pattern(tt.raw)
+		require.Nil(t, err, tt.raw)
+		require.Equal(t, tt.expected, actual, tt.raw)
+	}
+}
+
+func Test_Options(t *testing.T) {
+	t.Run("should keep disabled steps", func(t *testing.T) {
+		n := New(Options{TrailingSlash: TrailingSlashKeep})
+
+		actual, err := n.URL("https://example.ru/A//%D0%9E/./b/")
+		require.Nil(t, err)
+		require.Equal(t, "https://example.ru/A//%D0%9E/./b/", actual)
+
+		actual, err = New(Options{}).URL("/A//b/")
+		require.Nil(t, err)
+		require.Equal(t, "/A//b/", actual)
+	})
+
+	t.Run("should add trailing slash except after wildcard", func(t *testing.T) {
+		n := New(Options{TrailingSlash: TrailingSlashAdd})
+
+		actual, _ := n.URL("/a/b")
+		require.Equal(t, "/a/b/", actual)
+
+		actual, _ = n.Pattern("/a/:b")
+		require.Equal(t, "/a/:b/", actual)
+
+		actual, _ = n.Pattern("/a/*rest")
+		require.Equal(t, "/a/*rest", actual)
+
+		actual, _ = n.URL("/")
+		require.Equal(t, "/", actual)
+	})
+
+	t.Run("should return values as is for nil normalizer", func(t *testing.T) {
+		var n *Normalizer
+		actual, err := n.URL("/A//b/")
+		require.Nil(t, err)
+		require.Equal(t, "/A//b/", actual)
+	})
+
+	t.Run("should parse options", func(t *testing.T) {
+		opts, err := ParseOptions(DefaultSteps, "strip")
+		require.Nil(t, err)
+		require.Equal(t, DefaultOptions, opts)
+
+		opts, err = ParseOptions("case, slashes", "add")
+		require.Nil(t, err)
+		require.Equal(t, Options{Lowercase: true, CollapseSlashes: true, TrailingSlash: TrailingSlashAdd}, opts)
+
+		_, err = ParseOptions("unknown", "strip")
+		require.NotNil(t, err)
+
+		_, err = ParseOptions("", "none")
+		require.NotNil(t, err)
+	})
+}
+
+func Test_SplitHost(t *testing.T) {
+	tests := []struct {
+		raw  string
+		host string
+		rest string
+	}{
+		{"/catalog?x=1", "", "/catalog?x=1"},
+		{"https://Shop.example.ru:8443/catalog?x=1", "Shop.example.ru:8443", "/catalog?x=1"},
+		{"//example.ru", "example.ru", "/"},
+		{"http://example.ru?x=1", "example.ru", "/?x=1"},
+		{"http://example.ru#top", "example.ru", "/#top"},
+		{"catalog", "", "catalog"},
+	}
+
+	for _, tt := range tests {
+		host, rest := SplitHost(tt.raw)
+		require.Equal(t, tt.host, host, tt.raw)
+		require.Equal(t, tt.rest, rest, tt.raw)
+	}
+}
+
+func Test_TrailingSlashSearch(t *testing.T) {
+	tests := []struct {
+		url     string
+		pattern string
+		params  map[string]string
+	}{
+		{"/catalog", "/catalog/", map[string]string{}},
+		{"/catalog/", "/catalog/", map[string]string{}},
+		{"/catalog/shoes", "/catalog/:category/", map[string]string{"category": "shoes"}},
+		{"/catalog/shoes/", "/catalog/:category/", map[string]string{"category": "shoes"}},
+		{"/w/a/b/", "/w/*rest", map[string]string{"rest": "a/b"}},
+		{"/", "/", map[string]string{}},
+	}
+
+	for _, policy := range []TrailingSlash{TrailingSlashKeep, TrailingSlashStrip, TrailingSlashAdd} {
+		opts := DefaultOptions
+		opts.TrailingSlash = policy
+		n := New(opts)
+
+		trie := radixtrie.NewTrie()
+		patterns := make(map[string]string)
+		for _, raw := range []string{"/", "/catalog/", "/catalog/:category/", "/w/*rest"} {
+			pattern, err := n.Pattern(raw)
+			require.Nil(t, err, raw)
+			require.Nil(t, trie.Insert(pattern), pattern)
+			patterns[raw] = pattern
+		}
+
+		for _, tt := range tests {
+			url, err := n.URL(tt.url)
+			require.Nil(t, err, tt.url)
+
+			p := new(radixtrie.Params)
+			found := trie.Search(url, p)
+			require.NotNil(t, found, "policy %d, url %q", policy, url)
+			require.Equal(t, patterns[tt.pattern], found.String(), "policy %d, url %q", policy, url)
+			require.Equal(t, tt.params, p.Map(), "policy %d, url %q", policy, url)
+		}
+	}
+}
+
+func Test_LowercaseSearch(t *testing.T) {
+	n := New(DefaultOptions)
+
+	trie := radixtrie.NewTrie()
+	trie.SetCaseFolding(n.Options().Lowercase)
+	for _, raw := range []string{"/Catalog/:code<[A-Z]{2}>", "/Blog/:slug", "/A%2FB/*rest"} {
+		pattern, err := n.Pattern(raw)
+		require.Nil(t, err, raw)
+		require.Nil(t, trie.Insert(pattern), pattern)
+	}
+
+	tests := []struct {
+		url     string
+		pattern string
+		params  map[string]string
+	}{
+		{"/CATALOG/RU/", "/catalog/:code<[A-Z]{2}>", map[string]string{"code": "RU"}},
+		{"/catalog/ru", "", nil},
+		{"/blog/Red-Shoes", "/blog/:slug", map[string]string{"slug": "Red-Shoes"}},
+		{"/a%2fb/X/Y", "/a%2fb/*rest", map[string]string{"rest": "X/Y"}},
+	}
+
+	for _, tt := range tests {
+		url, err := n.URL(tt.url)
+		require.Nil(t, err, tt.url)
+
+		p := new(radixtrie.Params)
+		found := trie.Search(url, p)
+		if tt.pattern == "" {
+			require.Nil(t, found, url)
+			continue
+		}
+
+		require.NotNil(t, found, url)
+		require.Equal(t, tt.pattern, found.String(), url)
+		require.Equal(t, tt.params, p.Map(), url)
+	}
+}
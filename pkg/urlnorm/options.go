@@ -0,0 +1,69 @@
+package urlnorm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Названия шагов нормализации для ParseOptions
+const (
+	StepLowercase         = "case"
+	StepCollapseSlashes   = "slashes"
+	StepRemoveDotSegments = "dots"
+	StepDecodePercent     = "decode"
+	StepStripHost         = "host"
+)
+
+// DefaultSteps все шаги нормализации через запятую
+var DefaultSteps = strings.Join([]string{
+	StepLowercase,
+	StepCollapseSlashes,
+	StepRemoveDotSegments,
+	StepDecodePercent,
+	StepStripHost,
+}, ",")
+
+// ParseTrailingSlash разбирает политику завершающего слеша: keep, strip или add
+func ParseTrailingSlash(s string) (TrailingSlash, error) {
+	switch s {
+	case "keep":
+		return TrailingSlashKeep, nil
+	case "strip":
+		return TrailingSlashStrip, nil
+	case "add":
+		return TrailingSlashAdd, nil
+	default:
+		return 0, fmt.Errorf("unknown trailing slash policy %q", s)
+	}
+}
+
+// ParseOptions разбирает список шагов нормализации через запятую (см. Step* константы)
+// и политику завершающего слеша (см. ParseTrailingSlash)
+func ParseOptions(steps, trailingSlash string) (Options, error) {
+	var opts Options
+	for _, step := range strings.Split(steps, ",") {
+		switch strings.TrimSpace(step) {
+		case "":
+		case StepLowercase:
+			opts.Lowercase = true
+		case StepCollapseSlashes:
+			opts.CollapseSlashes = true
+		case StepRemoveDotSegments:
+			opts.RemoveDotSegments = true
+		case StepDecodePercent:
+			opts.DecodePercent = true
+		case StepStripHost:
+			opts.StripHost = true
+		default:
+			return Options{}, fmt.Errorf("unknown normalization step %q", step)
+		}
+	}
+
+	policy, err := ParseTrailingSlash(trailingSlash)
+	if err != nil {
+		return Options{}, err
+	}
+	opts.TrailingSlash = policy
+
+	return opts, nil
+}